@@ -246,13 +246,13 @@ func ExecuteContract(
 		return nil, nil, errors.Wrap(err, "failed to commit contracts to underlying db")
 	}
 	receipt.AddLogs(stateDB.Logs()...).AddTransactionLogs(depositLog, burnLog)
-	if receipt.Status == uint64(iotextypes.ReceiptStatus_Success) ||
-		featureCtx.AddOutOfGasToTransactionLog && receipt.Status == uint64(iotextypes.ReceiptStatus_ErrCodeStoreOutOfGas) {
+	if receipt.Status == action.ReceiptStatusSuccess ||
+		featureCtx.AddOutOfGasToTransactionLog && receipt.Status == action.ReceiptStatusErrCodeStoreOutOfGas {
 		receipt.AddTransactionLogs(stateDB.TransactionLogs()...)
 	}
 	stateDB.clear()
 
-	if featureCtx.SetRevertMessageToReceipt && receipt.Status == uint64(iotextypes.ReceiptStatus_ErrExecutionReverted) && retval != nil && bytes.Equal(retval[:4], revertSelector) {
+	if featureCtx.SetRevertMessageToReceipt && receipt.Status == action.ReceiptStatusErrExecutionReverted && retval != nil && bytes.Equal(retval[:4], revertSelector) {
 		// in case of the execution revert error, parse the retVal and add to receipt
 		data := retval[4:]
 		msgLength := byteutil.BytesToUint64BigEndian(data[56:64])
@@ -335,7 +335,7 @@ func executeInEVM(ctx context.Context, evmParams *Params, stateDB *StateDBAdapte
 	remainingGas := evmParams.gas
 	if err := securityDeposit(evmParams, stateDB, gasLimit); err != nil {
 		log.L().Warn("unexpected error: not enough security deposit", zap.Error(err))
-		return nil, 0, 0, action.EmptyAddress, uint64(iotextypes.ReceiptStatus_Failure), err
+		return nil, 0, 0, action.EmptyAddress, action.ReceiptStatusFailure, err
 	}
 	var (
 		config     vm.Config
@@ -351,10 +351,10 @@ func executeInEVM(ctx context.Context, evmParams *Params, stateDB *StateDBAdapte
 	}
 	intriGas, err := intrinsicGas(uint64(len(evmParams.data)), accessList)
 	if err != nil {
-		return nil, evmParams.gas, remainingGas, action.EmptyAddress, uint64(iotextypes.ReceiptStatus_Failure), err
+		return nil, evmParams.gas, remainingGas, action.EmptyAddress, action.ReceiptStatusFailure, err
 	}
 	if remainingGas < intriGas {
-		return nil, evmParams.gas, remainingGas, action.EmptyAddress, uint64(iotextypes.ReceiptStatus_Failure), action.ErrInsufficientFunds
+		return nil, evmParams.gas, remainingGas, action.EmptyAddress, action.ReceiptStatusFailure, action.ErrInsufficientFunds
 	}
 	remainingGas -= intriGas
 
@@ -389,7 +389,7 @@ func executeInEVM(ctx context.Context, evmParams *Params, stateDB *StateDBAdapte
 		// sufficient balance to make the transfer happen.
 		// Should be a hard fork (Bering)
 		if evmErr == vm.ErrInsufficientBalance && g.IsBering(blockHeight) {
-			return nil, evmParams.gas, remainingGas, action.EmptyAddress, uint64(iotextypes.ReceiptStatus_Failure), evmErr
+			return nil, evmParams.gas, remainingGas, action.EmptyAddress, action.ReceiptStatusFailure, evmErr
 		}
 	}
 	if stateDB.Error() != nil {
@@ -401,10 +401,10 @@ func executeInEVM(ctx context.Context, evmParams *Params, stateDB *StateDBAdapte
 	}
 	remainingGas += refund
 
-	errCode := uint64(iotextypes.ReceiptStatus_Success)
+	errCode := action.ReceiptStatusSuccess
 	if evmErr != nil {
 		errCode = evmErrToErrStatusCode(evmErr, g, blockHeight)
-		if errCode == uint64(iotextypes.ReceiptStatus_ErrUnknown) {
+		if errCode == action.ReceiptStatusErrUnknown {
 			var addr string
 			if evmParams.contract != nil {
 				ioAddr, _ := address.FromBytes((*evmParams.contract)[:])
@@ -425,34 +425,34 @@ func evmErrToErrStatusCode(evmErr error, g genesis.Blockchain, height uint64) (e
 	if g.IsJutland(height) {
 		switch evmErr {
 		case vm.ErrOutOfGas:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrOutOfGas)
+			errStatusCode = action.ReceiptStatusErrOutOfGas
 		case vm.ErrCodeStoreOutOfGas:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrCodeStoreOutOfGas)
+			errStatusCode = action.ReceiptStatusErrCodeStoreOutOfGas
 		case vm.ErrDepth:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrDepth)
+			errStatusCode = action.ReceiptStatusErrDepth
 		case vm.ErrContractAddressCollision:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrContractAddressCollision)
+			errStatusCode = action.ReceiptStatusErrContractAddressCollision
 		case vm.ErrExecutionReverted:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrExecutionReverted)
+			errStatusCode = action.ReceiptStatusErrExecutionReverted
 		case vm.ErrMaxCodeSizeExceeded:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrMaxCodeSizeExceeded)
+			errStatusCode = action.ReceiptStatusErrMaxCodeSizeExceeded
 		case vm.ErrWriteProtection:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrWriteProtection)
+			errStatusCode = action.ReceiptStatusErrWriteProtection
 		case vm.ErrInsufficientBalance:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrInsufficientBalance)
+			errStatusCode = action.ReceiptStatusErrInsufficientBalance
 		case vm.ErrInvalidJump:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrInvalidJump)
+			errStatusCode = action.ReceiptStatusErrInvalidJump
 		case vm.ErrReturnDataOutOfBounds:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrReturnDataOutOfBounds)
+			errStatusCode = action.ReceiptStatusErrReturnDataOutOfBounds
 		case vm.ErrGasUintOverflow:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrGasUintOverflow)
+			errStatusCode = action.ReceiptStatusErrGasUintOverflow
 		default:
 			//This errors from go-ethereum, are not-accessible variable.
 			switch evmErr.Error() {
 			case "no compatible interpreter":
-				errStatusCode = uint64(iotextypes.ReceiptStatus_ErrNoCompatibleInterpreter)
+				errStatusCode = action.ReceiptStatusErrNoCompatibleInterpreter
 			default:
-				errStatusCode = uint64(iotextypes.ReceiptStatus_ErrUnknown)
+				errStatusCode = action.ReceiptStatusErrUnknown
 			}
 		}
 		return
@@ -461,33 +461,33 @@ func evmErrToErrStatusCode(evmErr error, g genesis.Blockchain, height uint64) (e
 	if g.IsBering(height) {
 		switch evmErr {
 		case vm.ErrOutOfGas:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrOutOfGas)
+			errStatusCode = action.ReceiptStatusErrOutOfGas
 		case vm.ErrCodeStoreOutOfGas:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrCodeStoreOutOfGas)
+			errStatusCode = action.ReceiptStatusErrCodeStoreOutOfGas
 		case vm.ErrDepth:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrDepth)
+			errStatusCode = action.ReceiptStatusErrDepth
 		case vm.ErrContractAddressCollision:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrContractAddressCollision)
+			errStatusCode = action.ReceiptStatusErrContractAddressCollision
 		case vm.ErrExecutionReverted:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrExecutionReverted)
+			errStatusCode = action.ReceiptStatusErrExecutionReverted
 		case vm.ErrMaxCodeSizeExceeded:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrMaxCodeSizeExceeded)
+			errStatusCode = action.ReceiptStatusErrMaxCodeSizeExceeded
 		case vm.ErrWriteProtection:
-			errStatusCode = uint64(iotextypes.ReceiptStatus_ErrWriteProtection)
+			errStatusCode = action.ReceiptStatusErrWriteProtection
 		default:
 			//This errors from go-ethereum, are not-accessible variable.
 			switch evmErr.Error() {
 			case "no compatible interpreter":
-				errStatusCode = uint64(iotextypes.ReceiptStatus_ErrNoCompatibleInterpreter)
+				errStatusCode = action.ReceiptStatusErrNoCompatibleInterpreter
 			default:
-				errStatusCode = uint64(iotextypes.ReceiptStatus_ErrUnknown)
+				errStatusCode = action.ReceiptStatusErrUnknown
 			}
 		}
 		return
 	}
 
 	// before Bering height, return one common failure
-	errStatusCode = uint64(iotextypes.ReceiptStatus_Failure)
+	errStatusCode = action.ReceiptStatusFailure
 	return
 }
 
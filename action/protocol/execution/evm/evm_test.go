@@ -286,6 +286,12 @@ func TestEvmError(t *testing.T) {
 		r.Equal(evmErrToErrStatusCode(v.evmError, g, g.BeringBlockHeight), uint64(iotextypes.ReceiptStatus_ErrUnknown))
 		r.Equal(evmErrToErrStatusCode(v.evmError, g, g.BeringBlockHeight-1), uint64(iotextypes.ReceiptStatus_Failure))
 	}
+
+	// the executor writes the named receipt statuses
+	r.Equal(action.ReceiptStatusErrExecutionReverted, evmErrToErrStatusCode(vm.ErrExecutionReverted, g, g.JutlandBlockHeight))
+	r.Equal(action.ReceiptStatusErrOutOfGas, evmErrToErrStatusCode(vm.ErrOutOfGas, g, g.BeringBlockHeight))
+	r.Equal(action.ReceiptStatusErrUnknown, evmErrToErrStatusCode(vm.ErrInvalidJump, g, g.BeringBlockHeight))
+	r.Equal(action.ReceiptStatusFailure, evmErrToErrStatusCode(vm.ErrExecutionReverted, g, g.BeringBlockHeight-1))
 }
 
 func TestGasEstimate(t *testing.T) {
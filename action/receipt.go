@@ -9,6 +9,7 @@ package action
 import (
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/iotexproject/go-pkgs/hash"
@@ -26,6 +27,29 @@ var (
 	RewardingPoolTopic = hash.BytesToHash256(address.RewardingProtocolAddrHash[:])
 )
 
+// Receipt statuses written by the protocols, see iotextypes.ReceiptStatus for the full list
+const (
+	ReceiptStatusFailure = uint64(iotextypes.ReceiptStatus_Failure)
+	ReceiptStatusSuccess = uint64(iotextypes.ReceiptStatus_Success)
+
+	// EVM errors, written by the execution protocol since Bering instead of ReceiptStatusFailure
+	ReceiptStatusErrUnknown                  = uint64(iotextypes.ReceiptStatus_ErrUnknown)
+	ReceiptStatusErrOutOfGas                 = uint64(iotextypes.ReceiptStatus_ErrOutOfGas)
+	ReceiptStatusErrCodeStoreOutOfGas        = uint64(iotextypes.ReceiptStatus_ErrCodeStoreOutOfGas)
+	ReceiptStatusErrDepth                    = uint64(iotextypes.ReceiptStatus_ErrDepth)
+	ReceiptStatusErrContractAddressCollision = uint64(iotextypes.ReceiptStatus_ErrContractAddressCollision)
+	ReceiptStatusErrNoCompatibleInterpreter  = uint64(iotextypes.ReceiptStatus_ErrNoCompatibleInterpreter)
+	ReceiptStatusErrExecutionReverted        = uint64(iotextypes.ReceiptStatus_ErrExecutionReverted)
+	ReceiptStatusErrMaxCodeSizeExceeded      = uint64(iotextypes.ReceiptStatus_ErrMaxCodeSizeExceeded)
+	ReceiptStatusErrWriteProtection          = uint64(iotextypes.ReceiptStatus_ErrWriteProtection)
+
+	// EVM errors, written by the execution protocol since Jutland
+	ReceiptStatusErrInsufficientBalance   = uint64(iotextypes.ReceiptStatus_ErrInsufficientBalance)
+	ReceiptStatusErrInvalidJump           = uint64(iotextypes.ReceiptStatus_ErrInvalidJump)
+	ReceiptStatusErrReturnDataOutOfBounds = uint64(iotextypes.ReceiptStatus_ErrReturnDataOutOfBounds)
+	ReceiptStatusErrGasUintOverflow       = uint64(iotextypes.ReceiptStatus_ErrGasUintOverflow)
+)
+
 type (
 	// Topics are data items of a transaction, such as send/recipient address
 	Topics []hash.Hash256
@@ -99,6 +123,28 @@ func (receipt *Receipt) ConvertFromReceiptPb(pbReceipt *iotextypes.Receipt) {
 	receipt.executionRevertMsg = pbReceipt.GetExecutionRevertMsg()
 }
 
+// Clone returns a deep copy of the receipt, including its logs and transaction logs
+func (receipt *Receipt) Clone() *Receipt {
+	r := *receipt
+	if receipt.logs != nil {
+		r.logs = make([]*Log, len(receipt.logs))
+		for i, l := range receipt.logs {
+			r.logs[i] = l.clone()
+		}
+	}
+	if receipt.transactionLogs != nil {
+		r.transactionLogs = make([]*TransactionLog, len(receipt.transactionLogs))
+		for i, l := range receipt.transactionLogs {
+			tl := *l
+			if l.Amount != nil {
+				tl.Amount = new(big.Int).Set(l.Amount)
+			}
+			r.transactionLogs[i] = &tl
+		}
+	}
+	return &r
+}
+
 // Serialize returns a serialized byte stream for the Receipt
 func (receipt *Receipt) Serialize() ([]byte, error) {
 	return proto.Marshal(receipt.ConvertToReceiptPb())
@@ -123,11 +169,45 @@ func (receipt *Receipt) Hash() hash.Hash256 {
 	return hash.Hash256b(data)
 }
 
+// Succeeded returns true if the action of the receipt was executed successfully
+func (receipt *Receipt) Succeeded() bool {
+	return receipt.Status == ReceiptStatusSuccess
+}
+
 // Logs returns the list of logs stored in receipt
 func (receipt *Receipt) Logs() []*Log {
 	return receipt.logs
 }
 
+// LogsPage returns at most limit logs starting at offset, in the order of their index, and whether more logs
+// follow the page. An offset out of range returns an empty page.
+func (receipt *Receipt) LogsPage(offset, limit int) ([]*Log, bool) {
+	if offset < 0 || offset >= len(receipt.logs) || limit <= 0 {
+		return []*Log{}, false
+	}
+	end := len(receipt.logs)
+	if limit < end-offset {
+		end = offset + limit
+	}
+	return receipt.logs[offset:end:end], end < len(receipt.logs)
+}
+
+// LogsBloom returns the 256-byte Ethereum logs bloom of the receipt, over the address and the topics of each
+// log. The address is added as the 20 bytes of the io address, a log address which cannot be parsed is not
+// added. A receipt without logs has an all-zero bloom.
+func (receipt *Receipt) LogsBloom() []byte {
+	var bloom types.Bloom
+	for _, l := range receipt.logs {
+		if addr, err := address.FromString(l.Address); err == nil {
+			bloom.Add(addr.Bytes())
+		}
+		for _, topic := range l.Topics {
+			bloom.Add(topic[:])
+		}
+	}
+	return bloom.Bytes()
+}
+
 // AddLogs add log to receipt and filter out nil log.
 func (receipt *Receipt) AddLogs(logs ...*Log) *Receipt {
 	for _, l := range logs {
@@ -228,3 +308,16 @@ func (log *Log) Deserialize(buf []byte) error {
 	log.ConvertFromLogPb(pbLog)
 	return nil
 }
+
+func (log *Log) clone() *Log {
+	l := *log
+	if log.Topics != nil {
+		l.Topics = make(Topics, len(log.Topics))
+		copy(l.Topics, log.Topics)
+	}
+	if log.Data != nil {
+		l.Data = make([]byte, len(log.Data))
+		copy(l.Data, log.Data)
+	}
+	return &l
+}
@@ -8,11 +8,17 @@ package action
 
 import (
 	"encoding/hex"
+	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
 
 	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+
+	"github.com/iotexproject/iotex-core/test/identityset"
 )
 
 func newTestLog() *Log {
@@ -26,6 +32,15 @@ func newTestLog() *Log {
 
 }
 
+func newTestLogs(n int) []*Log {
+	logs := make([]*Log, n)
+	for i := range logs {
+		logs[i] = newTestLog()
+		logs[i].Index = uint32(i)
+	}
+	return logs
+}
+
 func TestConvert(t *testing.T) {
 	require := require.New(t)
 
@@ -131,6 +146,145 @@ func TestUpdateIndex(t *testing.T) {
 	}
 }
 
+func TestLogsPage(t *testing.T) {
+	require := require.New(t)
+	receipt := &Receipt{logs: newTestLogs(1000)}
+
+	var (
+		logs    []*Log
+		hasMore = true
+	)
+	for offset := 0; hasMore; offset += 300 {
+		var page []*Log
+		page, hasMore = receipt.LogsPage(offset, 300)
+		require.Equal(offset+300 < 1000, hasMore)
+		if hasMore {
+			require.Len(page, 300)
+		}
+		logs = append(logs, page...)
+	}
+	require.Len(logs, 1000)
+	for i, l := range logs {
+		require.Equal(uint32(i), l.Index)
+	}
+
+	page, hasMore := receipt.LogsPage(900, 100)
+	require.Len(page, 100)
+	require.False(hasMore)
+	page, hasMore = receipt.LogsPage(999, 0)
+	require.Empty(page)
+	require.False(hasMore)
+	for _, offset := range []int{-1, 1000, 2000} {
+		page, hasMore = receipt.LogsPage(offset, 10)
+		require.NotNil(page)
+		require.Empty(page)
+		require.False(hasMore)
+	}
+	page, hasMore = (&Receipt{}).LogsPage(0, 10)
+	require.Empty(page)
+	require.False(hasMore)
+}
+
+func TestReceiptLogsBloom(t *testing.T) {
+	require := require.New(t)
+
+	bloom := (&Receipt{}).LogsBloom()
+	require.Equal(make([]byte, types.BloomByteLength), bloom)
+
+	logs := newTestLogs(3)
+	for i, l := range logs {
+		l.Address = identityset.Address(i).String()
+		l.Topics = []hash.Hash256{hash.Hash256b([]byte{byte(i)})}
+	}
+	receipt := (&Receipt{}).AddLogs(logs...)
+	bloom = receipt.LogsBloom()
+	require.Len(bloom, types.BloomByteLength)
+	filter := types.BytesToBloom(bloom)
+	for i, l := range logs {
+		require.True(filter.Test(identityset.Address(i).Bytes()))
+		require.True(filter.Test(l.Topics[0][:]))
+	}
+	require.False(filter.Test(identityset.Address(10).Bytes()))
+	absent := hash.Hash256b([]byte("absent"))
+	require.False(filter.Test(absent[:]))
+
+	// same as the bloom of the equivalent Ethereum logs
+	ethLogs := make([]*types.Log, 0, len(logs))
+	for i, l := range logs {
+		ethLogs = append(ethLogs, &types.Log{
+			Address: common.BytesToAddress(identityset.Address(i).Bytes()),
+			Topics:  []common.Hash{common.BytesToHash(l.Topics[0][:])},
+		})
+	}
+	require.Equal(types.LogsBloom(ethLogs), bloom)
+}
+
+func TestReceiptStatus(t *testing.T) {
+	require := require.New(t)
+
+	// the values are persisted in receipts and must never change
+	for _, v := range []struct {
+		status   uint64
+		expected uint64
+	}{
+		{ReceiptStatusFailure, 0},
+		{ReceiptStatusSuccess, 1},
+		{ReceiptStatusErrUnknown, 100},
+		{ReceiptStatusErrOutOfGas, 101},
+		{ReceiptStatusErrCodeStoreOutOfGas, 102},
+		{ReceiptStatusErrDepth, 103},
+		{ReceiptStatusErrContractAddressCollision, 104},
+		{ReceiptStatusErrNoCompatibleInterpreter, 105},
+		{ReceiptStatusErrExecutionReverted, 106},
+		{ReceiptStatusErrMaxCodeSizeExceeded, 107},
+		{ReceiptStatusErrWriteProtection, 108},
+		{ReceiptStatusErrInsufficientBalance, 110},
+		{ReceiptStatusErrInvalidJump, 111},
+		{ReceiptStatusErrReturnDataOutOfBounds, 112},
+		{ReceiptStatusErrGasUintOverflow, 113},
+	} {
+		require.Equal(v.expected, v.status)
+		_, ok := iotextypes.ReceiptStatus_name[int32(v.status)]
+		require.True(ok)
+	}
+
+	receipt := &Receipt{Status: ReceiptStatusSuccess}
+	require.True(receipt.Succeeded())
+	for _, status := range []uint64{ReceiptStatusFailure, ReceiptStatusErrExecutionReverted, ReceiptStatusErrUnknown} {
+		receipt.Status = status
+		require.False(receipt.Succeeded())
+	}
+}
+
+func TestReceiptClone(t *testing.T) {
+	require := require.New(t)
+
+	receipt := &Receipt{
+		Status:      ReceiptStatusSuccess,
+		BlockHeight: 1,
+		ActionHash:  hash.Hash256b([]byte("action")),
+		GasConsumed: 10,
+	}
+	topic := hash.Hash256b([]byte("topic"))
+	receipt.AddLogs(&Log{Address: "1", Topics: Topics{topic}, Data: []byte{1, 2}})
+	receipt.AddTransactionLogs(&TransactionLog{Amount: big.NewInt(5), Sender: "a", Recipient: "b"})
+	receipt.SetExecutionRevertMsg("revert")
+
+	clone := receipt.Clone()
+	require.Equal(receipt, clone)
+
+	clone.Status = ReceiptStatusFailure
+	clone.Logs()[0].Topics[0] = hash.ZeroHash256
+	clone.Logs()[0].Data[0] = 9
+	clone.TransactionLogs()[0].Amount.SetInt64(7)
+	clone.AddLogs(newTestLog())
+	require.Equal(ReceiptStatusSuccess, receipt.Status)
+	require.Len(receipt.Logs(), 1)
+	require.Equal(topic, receipt.Logs()[0].Topics[0])
+	require.Equal([]byte{1, 2}, receipt.Logs()[0].Data)
+	require.Equal(int64(5), receipt.TransactionLogs()[0].Amount.Int64())
+}
+
 func TestConvertLog(t *testing.T) {
 	require := require.New(t)
 
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-address/address"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/pkg/log"
+)
+
+// actionIndex indexes the actions of a block by action hash
+type actionIndex struct {
+	actions  []action.SealedEnvelope
+	byAction map[hash.Hash256]uint32
+}
+
+// newActionIndex builds the index over the actions in block order, if an action appears more than once,
+// the first position wins
+func newActionIndex(actions []action.SealedEnvelope) *actionIndex {
+	idx := &actionIndex{
+		actions:  actions,
+		byAction: make(map[hash.Hash256]uint32, len(actions)),
+	}
+	for i := range actions {
+		h, err := actions[i].Hash()
+		if err != nil {
+			log.L().Debug("Skipping action due to hash error", zap.Error(err))
+			continue
+		}
+		if _, ok := idx.byAction[h]; !ok {
+			idx.byAction[h] = uint32(i)
+		}
+	}
+	return idx
+}
+
+// builtFrom returns true if the index was built from the given actions slice
+func (idx *actionIndex) builtFrom(actions []action.SealedEnvelope) bool {
+	if len(idx.actions) != len(actions) {
+		return false
+	}
+	return len(actions) == 0 || &idx.actions[0] == &actions[0]
+}
+
+// senderIndex holds the sender of each action of a block, with a single address instance per sender
+type senderIndex struct {
+	actions []action.SealedEnvelope
+	senders []address.Address
+}
+
+// newSenderIndex derives the sender of each action from its public key, the address of a public key is
+// derived once, and actions of the same sender share a single address instance
+func newSenderIndex(actions []action.SealedEnvelope) (*senderIndex, error) {
+	idx := &senderIndex{
+		actions: actions,
+		senders: make([]address.Address, len(actions)),
+	}
+	var (
+		byPubkey = make(map[string]address.Address)
+		byAddr   = make(map[string]address.Address)
+	)
+	for i := range actions {
+		pk := actions[i].SrcPubkey()
+		if pk == nil {
+			return nil, errors.Wrapf(ErrMissingSignature, "action %d has no sender", i)
+		}
+		key := string(pk.Bytes())
+		sender, ok := byPubkey[key]
+		if !ok {
+			if sender = pk.Address(); sender == nil {
+				return nil, errors.Errorf("failed to derive the sender of action %d", i)
+			}
+			// different encodings of a public key have the same address
+			if known, ok := byAddr[sender.String()]; ok {
+				sender = known
+			} else {
+				byAddr[sender.String()] = sender
+			}
+			byPubkey[key] = sender
+		}
+		idx.senders[i] = sender
+	}
+	return idx, nil
+}
+
+// builtFrom returns true if the index was built from the given actions slice
+func (idx *senderIndex) builtFrom(actions []action.SealedEnvelope) bool {
+	if len(idx.actions) != len(actions) {
+		return false
+	}
+	return len(actions) == 0 || &idx.actions[0] == &actions[0]
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+)
+
+// ErrEmptyTxRoot indicates the tx root is empty, so no action can be proven to be included
+var ErrEmptyTxRoot = errors.New("tx root is empty")
+
+// ActionProof is the merkle proof of the inclusion of an action in the tx root of a block
+type ActionProof struct {
+	ActionHash hash.Hash256
+	// Index is the position of the action in the block. Since the last node of a level of odd size is
+	// duplicated, the proof of the last action is also valid for the next index, verifiers who know the
+	// number of actions in the block should check the index against it.
+	Index uint32
+	// Siblings are the sibling nodes on the path from the action to the root, from the bottom up
+	Siblings []hash.Hash256
+}
+
+// ActionProof returns the proof of the inclusion of the i-th action in the tx root of the block
+func (b *Block) ActionProof(i uint32) (ActionProof, error) {
+	if int(i) >= len(b.Actions) {
+		return ActionProof{}, errors.Errorf("action index %d out of range [0, %d)", i, len(b.Actions))
+	}
+	leaves := make([]hash.Hash256, 0, len(b.Actions))
+	for _, selp := range b.Actions {
+		h, err := selp.Hash()
+		if err != nil {
+			return ActionProof{}, err
+		}
+		leaves = append(leaves, h)
+	}
+	return ActionProof{
+		ActionHash: leaves[i],
+		Index:      i,
+		Siblings:   merkleProof(leaves, int(i)),
+	}, nil
+}
+
+// VerifyActionProof returns true if the proof shows the action is included in txRoot
+func VerifyActionProof(txRoot hash.Hash256, proof ActionProof) bool {
+	return txRoot != hash.ZeroHash256 && proof.root(hashPair) == txRoot
+}
+
+// VerifyActionProofBatch verifies each proof against txRoot, and returns the result of each proof in the
+// same order. The proofs of actions in the same block share most of the upper nodes of the tree, each
+// pair of nodes is hashed only once for the whole batch.
+func VerifyActionProofBatch(txRoot hash.Hash256, proofs []ActionProof) ([]bool, error) {
+	if txRoot == hash.ZeroHash256 {
+		return nil, ErrEmptyTxRoot
+	}
+	var (
+		results = make([]bool, len(proofs))
+		pairs   = make(map[[2]hash.Hash256]hash.Hash256)
+		hasher  = func(left, right hash.Hash256) hash.Hash256 {
+			key := [2]hash.Hash256{left, right}
+			if h, ok := pairs[key]; ok {
+				return h
+			}
+			h := hashPair(left, right)
+			pairs[key] = h
+			return h
+		}
+	)
+	for i := range proofs {
+		results[i] = proofs[i].root(hasher) == txRoot
+	}
+	return results, nil
+}
+
+// root returns the root computed from the proof, or hash.ZeroHash256 if the index does not fit the proof
+func (p *ActionProof) root(hasher func(left, right hash.Hash256) hash.Hash256) hash.Hash256 {
+	return merkleProofRoot(p.ActionHash, p.Index, p.Siblings, hasher)
+}
+
+// merkleProof returns the sibling nodes on the path from the i-th leaf to the root of the tree computed by
+// crypto.NewMerkleTree, from the bottom up
+func merkleProof(leaves []hash.Hash256, i int) []hash.Hash256 {
+	var (
+		siblings []hash.Hash256
+		level    = make([]hash.Hash256, len(leaves), len(leaves)+1)
+	)
+	copy(level, leaves)
+	for pos := i; len(level) > 1; pos >>= 1 {
+		if len(level)&1 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		siblings = append(siblings, level[pos^1])
+		for j := 0; j < len(level)>>1; j++ {
+			level[j] = hashPair(level[j<<1], level[j<<1+1])
+		}
+		level = level[:len(level)>>1]
+	}
+	return siblings
+}
+
+// merkleProofRoot returns the root computed from a leaf, its index and the siblings on its path, or
+// hash.ZeroHash256 if the index does not fit the number of siblings
+func merkleProofRoot(leaf hash.Hash256, index uint32, siblings []hash.Hash256, hasher func(left, right hash.Hash256) hash.Hash256) hash.Hash256 {
+	pos := uint64(index)
+	if pos>>uint(len(siblings)) != 0 {
+		return hash.ZeroHash256
+	}
+	node := leaf
+	for _, sibling := range siblings {
+		if pos&1 == 0 {
+			node = hasher(node, sibling)
+		} else {
+			node = hasher(sibling, node)
+		}
+		pos >>= 1
+	}
+	return node
+}
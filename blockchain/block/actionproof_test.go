@@ -0,0 +1,92 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionProof(t *testing.T) {
+	require := require.New(t)
+
+	for _, n := range []int{1, 2, 3, 7, 8, 11} {
+		blk := makeBlock(t, n)
+		txRoot := blk.TxRoot()
+		for i := 0; i < n; i++ {
+			proof, err := blk.ActionProof(uint32(i))
+			require.NoError(err)
+			h, err := blk.Actions[i].Hash()
+			require.NoError(err)
+			require.Equal(h, proof.ActionHash)
+			require.True(VerifyActionProof(txRoot, proof), "%d of %d actions", i, n)
+		}
+		_, err := blk.ActionProof(uint32(n))
+		require.Error(err)
+	}
+}
+
+func TestVerifyActionProofBatch(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 11)
+	txRoot := blk.TxRoot()
+	var proofs []ActionProof
+	for i := range blk.Actions {
+		proof, err := blk.ActionProof(uint32(i))
+		require.NoError(err)
+		proofs = append(proofs, proof)
+	}
+	tampered := func(i int, f func(*ActionProof)) ActionProof {
+		p := proofs[i]
+		p.Siblings = append([]hash.Hash256{}, p.Siblings...)
+		f(&p)
+		return p
+	}
+	proofs = append(proofs,
+		// wrong action hash
+		tampered(0, func(p *ActionProof) { p.ActionHash = hash.Hash256b([]byte("wrong")) }),
+		// wrong sibling on a path shared with valid proofs
+		tampered(1, func(p *ActionProof) { p.Siblings[2] = hash.Hash256b([]byte("wrong")) }),
+		// wrong index
+		tampered(4, func(p *ActionProof) { p.Index = 5 }),
+		// index does not fit the proof
+		tampered(4, func(p *ActionProof) { p.Index = 1 << len(p.Siblings) }),
+		// truncated and extended proofs
+		tampered(6, func(p *ActionProof) { p.Siblings = p.Siblings[:len(p.Siblings)-1] }),
+		tampered(6, func(p *ActionProof) { p.Siblings = append(p.Siblings, txRoot) }),
+		// proof of another block
+		func() ActionProof {
+			p, err := makeBlock(t, 3).ActionProof(1)
+			require.NoError(err)
+			return p
+		}(),
+	)
+	// valid proofs after invalid ones
+	proofs = append(proofs, proofs[3], proofs[10])
+
+	results, err := VerifyActionProofBatch(txRoot, proofs)
+	require.NoError(err)
+	require.Len(results, len(proofs))
+	valid := 0
+	for i := range proofs {
+		require.Equal(VerifyActionProof(txRoot, proofs[i]), results[i], "proof %d", i)
+		if results[i] {
+			valid++
+		}
+	}
+	require.Equal(len(blk.Actions)+2, valid)
+
+	results, err = VerifyActionProofBatch(txRoot, nil)
+	require.NoError(err)
+	require.Empty(results)
+	_, err = VerifyActionProofBatch(hash.ZeroHash256, proofs)
+	require.Equal(ErrEmptyTxRoot, errors.Cause(err))
+}
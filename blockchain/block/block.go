@@ -7,9 +7,18 @@
 package block
 
 import (
+	"bytes"
 	"encoding/hex"
+	"math"
+	"math/big"
+	"math/bits"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-address/address"
 	"github.com/iotexproject/iotex-proto/golang/iotextypes"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -17,7 +26,71 @@ import (
 
 	"github.com/iotexproject/iotex-core/action"
 	"github.com/iotexproject/iotex-core/endorsement"
+	"github.com/iotexproject/iotex-core/pkg/compress"
 	"github.com/iotexproject/iotex-core/pkg/log"
+	"github.com/iotexproject/iotex-core/pkg/version"
+)
+
+// Format versions of the byte stream written by Block.Serialize
+const (
+	// FormatVersion1 is the protobuf encoding of the block
+	FormatVersion1 byte = 1
+	// CurrentFormatVersion is the format version written by Serialize
+	CurrentFormatVersion = FormatVersion1
+
+	// a protobuf message never starts with a byte below 0x08 (field number 0 is invalid), so a format
+	// version up to this value can be told apart from legacy byte streams without prefix
+	maxFormatVersion byte = 0x07
+)
+
+// Costs of DataGas, in gas per byte of action data. IoTeX charges every byte the same as action.ExecutionDataGas,
+// the zero byte cost can be set apart to study a model which charges zero bytes less, like Ethereum calldata.
+var (
+	DataGasPerZeroByte    = action.ExecutionDataGas
+	DataGasPerNonZeroByte = action.ExecutionDataGas
+)
+
+// ForwardCompatVersions is the number of protocol versions ahead of version.ProtocolVersion a block header may
+// have and still be loaded by ConvertFromBlockPb, so a node lagging a minor upgrade can read blocks produced by
+// upgraded nodes, as long as the body format is unchanged
+var ForwardCompatVersions uint32 = 1
+
+// Weights of EstimatedStateDelta, in bytes
+const (
+	// StateDeltaPerTransfer is the estimated state growth of a transfer
+	StateDeltaPerTransfer int64 = 32
+	// StateDeltaPerExecution is the estimated state growth of an execution, besides the logs it emits
+	StateDeltaPerExecution int64 = 128
+	// StateDeltaPerDeployment is the estimated state growth of a contract deployment, besides its code
+	StateDeltaPerDeployment int64 = 1024
+	// StateDeltaPerLog is the estimated state growth of a log, as a proxy of a storage write
+	StateDeltaPerLog int64 = 64
+)
+
+// Errors
+var (
+	ErrUnsupportedFormatVersion = errors.New("unsupported block format version")
+	ErrTimestampBeforeParent    = errors.New("block timestamp is not after its parent")
+	ErrTimestampInFuture        = errors.New("block timestamp is too far in the future")
+	ErrUnsupportedVersion       = errors.New("unsupported block version")
+	ErrVersionTooOld            = errors.New("block version is below the minimum")
+	ErrZeroTimestamp            = errors.New("block timestamp is zero")
+	ErrMissingProducer          = errors.New("block producer public key is missing")
+	ErrMissingSignature         = errors.New("signature is missing")
+	ErrInvalidSignature         = errors.New("signature is invalid")
+	ErrParentHeightMismatch     = errors.New("block height does not follow its parent")
+	ErrPrevHashMismatch         = errors.New("block prev hash does not match its parent")
+	ErrActionIndexOutOfRange    = errors.New("action index out of range")
+	ErrReceiptMismatch          = errors.New("receipts do not match actions")
+	ErrDuplicateAction          = errors.New("duplicate action in block")
+	ErrBlockSealed              = errors.New("block is sealed")
+	ErrUnauthorizedProducer     = errors.New("block producer is not an allowed delegate")
+	ErrBlockGasExceeded         = errors.New("block gas exceeds the limit")
+	ErrInsufficientEndorsements = errors.New("not enough delegates endorsed the block")
+	ErrGasPriceTooLow           = errors.New("action gas price is below the floor")
+	ErrNonceGap                 = errors.New("action nonces are not contiguous")
+	ErrWrongSlot                = errors.New("block timestamp is outside the slot")
+	ErrInvalidProducerSignature = errors.New("block is not signed by its producer")
 )
 
 // Block defines the struct of block
@@ -27,7 +100,63 @@ type Block struct {
 	Footer
 
 	// TODO: move receipts out of block struct
+	// Receipts are not part of the block hash
 	Receipts []*action.Receipt
+
+	receiptIdx atomic.Value // memoized *receiptIndex built from Receipts
+	actionIdx  atomic.Value // memoized *actionIndex built from Actions
+	senderIdx  atomic.Value // memoized *senderIndex built from Actions
+	sealed     int32        // set to 1 by Seal
+}
+
+// Clone returns a copy of the block which can be modified without affecting the original. The header, the
+// action and endorsement slices and the receipts are copied, while the signed actions, the endorsements and
+// the producer's public key are immutable and shared.
+func (b *Block) Clone() *Block {
+	clone := &Block{
+		Header: b.Header,
+		Body: Body{
+			Actions: append([]action.SealedEnvelope(nil), b.Actions...),
+		},
+		Footer: Footer{
+			endorsements: append([]*endorsement.Endorsement(nil), b.endorsements...),
+			commitTime:   b.commitTime,
+		},
+	}
+	clone.blockSig = append([]byte(nil), b.blockSig...)
+	clone.extraData = append([]byte(nil), b.extraData...)
+	clone.logsBloom = cloneBloom(b.logsBloom)
+	if b.Receipts != nil {
+		clone.Receipts = make([]*action.Receipt, len(b.Receipts))
+		for i, r := range b.Receipts {
+			if r != nil {
+				clone.Receipts[i] = r.Clone()
+			}
+		}
+	}
+	return clone
+}
+
+// NewHeaderOnlyBlock returns a block with a copy of the header and an empty body, for header-first sync where
+// the body is fetched later. The actions are an empty slice and the receipts are nil.
+func NewHeaderOnlyBlock(h *Header) *Block {
+	blk := &Block{
+		Header: *h,
+		Body: Body{
+			Actions: []action.SealedEnvelope{},
+		},
+	}
+	blk.blockSig = append([]byte(nil), h.blockSig...)
+	blk.extraData = append([]byte(nil), h.extraData...)
+	blk.logsBloom = cloneBloom(h.logsBloom)
+	return blk
+}
+
+// IsHeaderOnly returns true if the block has no action while its tx root is not empty, i.e. the header commits
+// to actions the block does not carry, like a block created by NewHeaderOnlyBlock. The body of a block without
+// actions is empty anyway, so such a block is never header-only.
+func (b *Block) IsHeaderOnly() bool {
+	return len(b.Actions) == 0 && b.txRoot != hash.ZeroHash256
 }
 
 // ConvertToBlockHeaderPb converts BlockHeader to BlockHeader
@@ -48,17 +177,32 @@ func (b *Block) ConvertToBlockPb() *iotextypes.Block {
 	}
 }
 
-// Serialize returns the serialized byte stream of the block
+// Serialize returns the serialized byte stream of the block, prefixed with CurrentFormatVersion
 func (b *Block) Serialize() ([]byte, error) {
-	return proto.Marshal(b.ConvertToBlockPb())
+	ser, err := proto.Marshal(b.ConvertToBlockPb())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{CurrentFormatVersion}, ser...), nil
 }
 
 // ConvertFromBlockPb converts Block to Block
+// A header version ahead of version.ProtocolVersion is accepted with a warning within ForwardCompatVersions,
+// and fails with ErrUnsupportedFormatVersion beyond.
 func (b *Block) ConvertFromBlockPb(pbBlock *iotextypes.Block) error {
 	b.Header = Header{}
 	if err := b.Header.LoadFromBlockHeaderProto(pbBlock.GetHeader()); err != nil {
 		return err
 	}
+	if v := b.Version(); v > version.ProtocolVersion {
+		if v-version.ProtocolVersion > ForwardCompatVersions {
+			return errors.Wrapf(ErrUnsupportedFormatVersion, "block version %d, expecting at most %d", v, version.ProtocolVersion+ForwardCompatVersions)
+		}
+		log.L().Warn("Loading block of a newer protocol version",
+			zap.Uint64("height", b.Height()),
+			zap.Uint32("version", v),
+			zap.Uint32("protocolVersion", version.ProtocolVersion))
+	}
 	b.Body = Body{}
 	if err := b.Body.LoadProto(pbBlock.GetBody()); err != nil {
 		return err
@@ -67,8 +211,219 @@ func (b *Block) ConvertFromBlockPb(pbBlock *iotextypes.Block) error {
 	return b.ConvertFromBlockFooterPb(pbBlock.GetFooter())
 }
 
-// Deserialize parses the byte stream into a Block
+// ConvertFromBlockPbLenient converts Block from protobuf like ConvertFromBlockPb, except that actions which cannot
+// be loaded are skipped instead of failing the conversion, and their indices in the protobuf are returned. The
+// tx root of the block does not match if any action was skipped. It is meant for inspecting damaged data only.
+func (b *Block) ConvertFromBlockPbLenient(pbBlock *iotextypes.Block) ([]int, error) {
+	b.Header = Header{}
+	if err := b.Header.LoadFromBlockHeaderProto(pbBlock.GetHeader()); err != nil {
+		return nil, err
+	}
+	pbActs := pbBlock.GetBody().GetActions()
+	if uint64(len(pbActs)) > MaxActionsPerBlock {
+		return nil, errors.Wrapf(ErrTooManyActions, "%d actions exceeds limit %d", len(pbActs), MaxActionsPerBlock)
+	}
+	b.Body = Body{Actions: make([]action.SealedEnvelope, 0, len(pbActs))}
+	skipped := []int{}
+	for i, actPb := range pbActs {
+		act := action.SealedEnvelope{}
+		if err := act.LoadProto(actPb); err != nil {
+			log.L().Debug("Skipping invalid action", zap.Int("index", i), zap.Error(err))
+			skipped = append(skipped, i)
+			continue
+		}
+		b.Actions = append(b.Actions, act)
+	}
+	return skipped, b.ConvertFromBlockFooterPb(pbBlock.GetFooter())
+}
+
+// SerializeForNetwork returns the protobuf encoding of the block, which is Serialize without the format version
+// prefix. Neither form carries receipts, since ConvertToBlockPb never includes them; only Store does. Receivers
+// recompute the receipts and verify them against the receipt root in the header.
+func (b *Block) SerializeForNetwork() ([]byte, error) {
+	return proto.Marshal(b.ConvertToBlockPb())
+}
+
+// CompressionRatio returns the size of the serialized block divided by its size compressed with codec.
+// A block without actions has nothing worth compressing, the ratio is 1.0.
+func (b *Block) CompressionRatio(codec compress.Codec) (float64, error) {
+	ser, err := b.Serialize()
+	if err != nil {
+		return 0, err
+	}
+	compressed, err := codec.Compress(ser)
+	if err != nil {
+		return 0, err
+	}
+	if len(b.Actions) == 0 || len(compressed) == 0 {
+		return 1.0, nil
+	}
+	return float64(len(ser)) / float64(len(compressed)), nil
+}
+
+// EstimateStorage returns the total serialized size of blocks and their total size compressed with codec. The
+// blocks are serialized and compressed one at a time, so only one block's buffers are held at once.
+func EstimateStorage(blocks []*Block, codec compress.Codec) (uncompressed, compressed int64, err error) {
+	for i, blk := range blocks {
+		if blk == nil {
+			return 0, 0, errors.Errorf("block %d is nil", i)
+		}
+		ser, err := blk.Serialize()
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to serialize block %d", blk.Height())
+		}
+		comp, err := codec.Compress(ser)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to compress block %d", blk.Height())
+		}
+		uncompressed += int64(len(ser))
+		compressed += int64(len(comp))
+	}
+	return uncompressed, compressed, nil
+}
+
+// compressedCodecTags are the tags of the codecs embedded by SerializeCompressed, a tag must never be reused
+var compressedCodecTags = map[compress.Codec]byte{
+	compress.Gzip:   1,
+	compress.Snappy: 2,
+}
+
+// SerializeCompressed returns the byte stream of Serialize compressed with codec, prefixed with a tag of the
+// codec so DeserializeCompressed needs not be told the codec
+func (b *Block) SerializeCompressed(codec compress.Codec) ([]byte, error) {
+	tag, ok := compressedCodecTags[codec]
+	if !ok {
+		return nil, errors.Wrapf(compress.ErrUnsupportedCodec, "codec %q", codec)
+	}
+	ser, err := proto.MarshalOptions{}.MarshalAppend([]byte{CurrentFormatVersion}, b.ConvertToBlockPb())
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := codec.Compress(ser)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, 1+len(compressed)), tag), compressed...), nil
+}
+
+// DeserializeCompressed parses the byte stream produced by SerializeCompressed into a Block
+func DeserializeCompressed(buf []byte) (*Block, error) {
+	if len(buf) == 0 {
+		return nil, errors.Wrap(compress.ErrUnsupportedCodec, "missing codec tag")
+	}
+	for codec, tag := range compressedCodecTags {
+		if tag != buf[0] {
+			continue
+		}
+		ser, err := codec.Decompress(buf[1:])
+		if err != nil {
+			return nil, err
+		}
+		blk := &Block{}
+		if err := blk.Deserialize(ser); err != nil {
+			return nil, err
+		}
+		return blk, nil
+	}
+	return nil, errors.Wrapf(compress.ErrUnsupportedCodec, "codec tag %d", buf[0])
+}
+
+// SerializeActions returns the serialized byte stream of the actions in the block, without header, footer and
+// receipts, for peers reconciling their action pools
+func (b *Block) SerializeActions() ([]byte, error) {
+	return b.Body.Serialize()
+}
+
+// DeserializeActions parses the byte stream produced by SerializeActions into actions
+func DeserializeActions(buf []byte) ([]action.SealedEnvelope, error) {
+	body := Body{}
+	if err := body.Deserialize(buf); err != nil {
+		return nil, err
+	}
+	return body.Actions, nil
+}
+
+// Deserialize parses the byte stream produced by Serialize into a Block. A byte stream without format
+// version prefix is decoded as legacy protobuf, which is the same encoding as FormatVersion1.
 func (b *Block) Deserialize(buf []byte) error {
+	if len(buf) == 0 || buf[0] > maxFormatVersion {
+		return b.DeserializeFromNetwork(buf)
+	}
+	switch buf[0] {
+	case FormatVersion1:
+		return b.DeserializeFromNetwork(buf[1:])
+	default:
+		return errors.Wrapf(ErrUnsupportedFormatVersion, "format version %d", buf[0])
+	}
+}
+
+// DeserializeWithClock parses the byte stream produced by Serialize into a Block, and rejects the block with
+// ErrTimestampInFuture if its timestamp is more than maxDrift ahead of now()
+func DeserializeWithClock(raw []byte, now func() time.Time, maxDrift time.Duration) (*Block, error) {
+	blk := &Block{}
+	if err := blk.Deserialize(raw); err != nil {
+		return nil, err
+	}
+	if err := blk.verifyTimestampNotAfter(now().Add(maxDrift)); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
+
+// DeserializeBatch parses the byte streams produced by Serialize into blocks concurrently, using at most workers
+// goroutines, and returns the blocks in the order of raws. A failure stops the workers from taking more byte
+// streams, the error returned is the one of the lowest index that failed.
+func DeserializeBatch(raws [][]byte, workers int) ([]*Block, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(raws) {
+		workers = len(raws)
+	}
+	var (
+		blks     = make([]*Block, len(raws))
+		next     = int64(-1)
+		failed   int32
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errIndex int
+		err      error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&failed) == 0 {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(raws) {
+					return
+				}
+				blk := &Block{}
+				if e := blk.Deserialize(raws[i]); e != nil {
+					atomic.StoreInt32(&failed, 1)
+					mu.Lock()
+					if err == nil || i < errIndex {
+						errIndex, err = i, e
+					}
+					mu.Unlock()
+					return
+				}
+				blks[i] = blk
+			}
+		}()
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to deserialize block %d", errIndex)
+	}
+	return blks, nil
+}
+
+// DeserializeFromNetwork parses the byte stream produced by SerializeForNetwork into a Block
+func (b *Block) DeserializeFromNetwork(buf []byte) error {
+	if err := verifyActionCount(buf, true); err != nil {
+		return err
+	}
 	pbBlock := iotextypes.Block{}
 	if err := proto.Unmarshal(buf, &pbBlock); err != nil {
 		return err
@@ -95,6 +450,247 @@ func (b *Block) VerifyTxRoot() error {
 	return nil
 }
 
+// VerifyDeltaStateDigest verifies the delta state digest in the header matches the digest recomputed by
+// executing the block, and returns ErrDeltaStateMismatch with both digests otherwise. It replaces the boolean
+// check of Header.
+func (b *Block) VerifyDeltaStateDigest(recomputed hash.Hash256) error {
+	if !b.Header.VerifyDeltaStateDigest(recomputed) {
+		return errors.Wrapf(ErrDeltaStateMismatch, "block %d has digest %x, recomputed %x", b.Height(), b.DeltaStateDigest(), recomputed)
+	}
+	return nil
+}
+
+// VerifyTimestampAfter verifies the block timestamp is strictly after the parent's, and no more than maxDrift
+// ahead of the local clock
+func (b *Block) VerifyTimestampAfter(parent *Header, maxDrift time.Duration) error {
+	ts := b.Timestamp()
+	if !ts.After(parent.Timestamp()) {
+		return errors.Wrapf(ErrTimestampBeforeParent, "block %s, parent %s", ts, parent.Timestamp())
+	}
+	return b.verifyTimestampNotAfter(time.Now().Add(maxDrift))
+}
+
+func (b *Block) verifyTimestampNotAfter(limit time.Time) error {
+	if ts := b.Timestamp(); ts.After(limit) {
+		return errors.Wrapf(ErrTimestampInFuture, "block %s, limit %s", ts, limit)
+	}
+	return nil
+}
+
+// Age returns the time elapsed from the block timestamp to now, or zero if the block timestamp is after now
+func (b *Block) Age(now time.Time) time.Duration {
+	if age := now.Sub(b.Timestamp()); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// VerifySlot returns ErrWrongSlot if the block timestamp is not within expectedSlot of the schedule, where slot n
+// spans [epochStart + n*slotInterval, epochStart + (n+1)*slotInterval). The schedule is given by the caller, such
+// as the RollDPoS round calculator.
+func (b *Block) VerifySlot(epochStart time.Time, slotInterval time.Duration, expectedSlot uint64) error {
+	if slotInterval <= 0 {
+		return errors.Errorf("invalid slot interval %s", slotInterval)
+	}
+	ts := b.Timestamp()
+	if ts.Before(epochStart) {
+		return errors.Wrapf(ErrWrongSlot, "timestamp %s is before epoch start %s", ts, epochStart)
+	}
+	if slot := uint64(ts.Sub(epochStart) / slotInterval); slot != expectedSlot {
+		return errors.Wrapf(ErrWrongSlot, "timestamp %s is in slot %d, expecting slot %d", ts, slot, expectedSlot)
+	}
+	return nil
+}
+
+// VerifyProducerSignature returns ErrInvalidProducerSignature if the block signature does not verify against the
+// header core hash and the producer public key. Action signatures are not checked.
+func (b *Block) VerifyProducerSignature() error {
+	if b.pubkey == nil {
+		return errors.Wrapf(ErrMissingProducer, "block %d", b.Height())
+	}
+	h := b.HashHeaderCore()
+	if !b.pubkey.Verify(h[:], b.blockSig) {
+		return errors.Wrapf(ErrInvalidProducerSignature, "block %d", b.Height())
+	}
+	return nil
+}
+
+// TPS returns the number of actions of the block per second of the interval since its parent, and
+// ErrTimestampBeforeParent if the interval is not positive
+func (b *Block) TPS(parent *Header) (float64, error) {
+	interval := b.Timestamp().Sub(parent.Timestamp())
+	if interval <= 0 {
+		return 0, errors.Wrapf(ErrTimestampBeforeParent, "block %s, parent %s", b.Timestamp(), parent.Timestamp())
+	}
+	return float64(len(b.Actions)) / interval.Seconds(), nil
+}
+
+// VerifyParent verifies the block links to the given parent, by height and by prev hash
+func (b *Block) VerifyParent(parent *Block) error {
+	if parent.Height() == math.MaxUint64 || b.Height() != parent.Height()+1 {
+		return errors.Wrapf(ErrParentHeightMismatch, "height %d, parent height %d", b.Height(), parent.Height())
+	}
+	if h := parent.HashBlock(); b.PrevHash() != h {
+		return errors.Wrapf(ErrPrevHashMismatch, "prev hash %x, parent hash %x", b.PrevHash(), h)
+	}
+	return nil
+}
+
+// VerifyMinVersion verifies the block version is at least min, for validators enforcing an upgrade
+func (b *Block) VerifyMinVersion(min uint32) error {
+	if v := b.Version(); v < min {
+		return errors.Wrapf(ErrVersionTooOld, "version %d, minimum %d", v, min)
+	}
+	return nil
+}
+
+// ValidateBasic runs the structural checks that only need the block itself, and returns the first violation:
+// the version is supported, the timestamp is set, a non-genesis block carries its producer's public key and
+// signature, every action carries its sender's public key and signature, and the tx root matches the actions.
+// Signatures are checked for presence only, not verified, and nothing that needs chain state or execution is
+// covered, e.g. the height and parent hash, the timestamp against the parent, the delta state digest and the
+// receipt root.
+func (b *Block) ValidateBasic() error {
+	if b.Version() != version.ProtocolVersion {
+		return errors.Wrapf(ErrUnsupportedVersion, "version %d, expecting %d", b.Version(), version.ProtocolVersion)
+	}
+	if b.Timestamp().IsZero() {
+		return errors.Wrapf(ErrZeroTimestamp, "block %d", b.Height())
+	}
+	if b.Height() > 0 {
+		if b.PublicKey() == nil {
+			return errors.Wrapf(ErrMissingProducer, "block %d", b.Height())
+		}
+		if len(b.blockSig) == 0 {
+			return errors.Wrapf(ErrMissingSignature, "block %d", b.Height())
+		}
+	}
+	for i := range b.Actions {
+		selp := &b.Actions[i]
+		if selp.SrcPubkey() == nil {
+			return errors.Wrapf(ErrMissingSignature, "action %d has no sender public key", i)
+		}
+		if len(selp.Signature()) == 0 {
+			return errors.Wrapf(ErrMissingSignature, "action %d", i)
+		}
+	}
+	return errors.Wrapf(b.VerifyTxRoot(), "block %d", b.Height())
+}
+
+// VerifyIntrinsicGas verifies the gas limit of every action covers its intrinsic gas, which depends on the
+// action type and its data size, as checked by the generic action validator before execution. It returns
+// action.ErrIntrinsicGas for the first offending action.
+func (b *Block) VerifyIntrinsicGas() error {
+	for i := range b.Actions {
+		selp := &b.Actions[i]
+		if selp.Envelope == nil {
+			return errors.Errorf("action %d has no envelope", i)
+		}
+		intrinsicGas, err := selp.IntrinsicGas()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get intrinsic gas of action %d", i)
+		}
+		if intrinsicGas > selp.GasLimit() {
+			return errors.Wrapf(action.ErrIntrinsicGas, "action %d has gas limit %d, intrinsic gas %d", i, selp.GasLimit(), intrinsicGas)
+		}
+	}
+	return nil
+}
+
+// VerifyProducerIn verifies the producer of the block, derived from the public key in the header, is one of the
+// delegates, which are keyed by their io address string
+func (b *Block) VerifyProducerIn(delegates map[string]struct{}) error {
+	if b.pubkey == nil {
+		return ErrMissingProducer
+	}
+	producer := b.pubkey.Address()
+	if producer == nil {
+		return errors.Wrap(ErrUnauthorizedProducer, "failed to derive the producer address")
+	}
+	if _, ok := delegates[producer.String()]; !ok {
+		return errors.Wrapf(ErrUnauthorizedProducer, "producer %s at height %d", producer.String(), b.Height())
+	}
+	return nil
+}
+
+// HashProposal returns the hash of the proposed block, which is what endorsers sign during consensus.
+// It is the hash of the header, which commits to the body through the tx root, and it does not change
+// when endorsements are added to the footer. Verify the tx root of a block received from a peer before
+// relying on the hash to cover its actions.
+func (b *Block) HashProposal() hash.Hash256 {
+	return b.HashBlock()
+}
+
+// BlockLink is the position of a block in the chain
+type BlockLink struct {
+	Height uint64
+	// Hash is the block hash returned by HashBlock, which is the hash of the header
+	Hash     hash.Hash256
+	PrevHash hash.Hash256
+}
+
+// Link returns the height, the hash and the previous hash of the block
+func (b *Block) Link() BlockLink {
+	return BlockLink{
+		Height:   b.Height(),
+		Hash:     b.HashBlock(),
+		PrevHash: b.PrevHash(),
+	}
+}
+
+// BlockSummary is the summary of a block for block list API responses
+type BlockSummary struct {
+	Height    uint64
+	Hash      hash.Hash256
+	Timestamp time.Time
+	// Producer is the io address of the producer, empty if the block has no producer public key
+	Producer    string
+	NumActions  int
+	GasConsumed uint64
+	// TransferAmount is the value returned by TotalTransferAmount, nil if an action has no envelope
+	TransferAmount *big.Int
+}
+
+// Summary returns the summary of the block. The gas consumed is summed over the receipts attached to the
+// block, as TotalGasConsumed does.
+func (b *Block) Summary() BlockSummary {
+	summary := BlockSummary{
+		Height:      b.Height(),
+		Hash:        b.HashBlock(),
+		Timestamp:   b.Timestamp(),
+		NumActions:  len(b.Actions),
+		GasConsumed: b.TotalGasConsumed(),
+	}
+	if b.pubkey != nil {
+		summary.Producer = b.ProducerAddress()
+	}
+	if amount, err := b.TotalTransferAmount(); err == nil {
+		summary.TransferAmount = amount
+	}
+	return summary
+}
+
+// CompareBlocks orders blocks by height, then by block hash as big-endian bytes, and returns -1, 0 or 1 if a
+// is before, equal to or after b. At the same height, the block ordered first is the preferred one when the
+// protocol leaves the choice to the node, which is the same on all nodes since it only depends on the blocks.
+// A nil block is after any other block.
+func CompareBlocks(a, b *Block) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case a.Height() < b.Height():
+		return -1
+	case a.Height() > b.Height():
+		return 1
+	}
+	ha, hb := a.HashBlock(), b.HashBlock()
+	return bytes.Compare(ha[:], hb[:])
+}
+
 // RunnableActions abstructs RunnableActions from a Block.
 func (b *Block) RunnableActions() RunnableActions {
 	return RunnableActions{actions: b.Actions, txHash: b.txRoot}
@@ -102,6 +698,9 @@ func (b *Block) RunnableActions() RunnableActions {
 
 // Finalize creates a footer for the block
 func (b *Block) Finalize(endorsements []*endorsement.Endorsement, ts time.Time) error {
+	if b.Sealed() {
+		return ErrBlockSealed
+	}
 	if len(b.endorsements) != 0 {
 		return errors.New("the block has been finalized")
 	}
@@ -111,6 +710,85 @@ func (b *Block) Finalize(endorsements []*endorsement.Endorsement, ts time.Time)
 	return nil
 }
 
+// AddEndorsement adds a commit endorsement to the footer, after verifying it endorses the commit vote
+// on HashProposal. An endorsement from an endorser already in the footer is ignored.
+func (b *Block) AddEndorsement(en *endorsement.Endorsement) error {
+	if b.Sealed() {
+		return ErrBlockSealed
+	}
+	if en == nil || en.Endorser() == nil {
+		return errors.Wrap(ErrInvalidEndorsement, "missing endorser")
+	}
+	if !endorsement.VerifyEndorsement(commitVote(b.HashProposal()), en) {
+		return errors.Wrapf(ErrInvalidEndorsement, "endorsement of %x does not match block %x", en.Endorser().Bytes(), b.HashProposal())
+	}
+	endorser := en.Endorser().Bytes()
+	for _, e := range b.endorsements {
+		if bytes.Equal(e.Endorser().Bytes(), endorser) {
+			return nil
+		}
+	}
+	b.endorsements = append(b.endorsements, en)
+	return nil
+}
+
+// VerifyEndorsementQuorum verifies more than threshold of the delegates, which are io address strings, endorsed
+// the block in the footer, like consensus requires more than 2/3 of the delegates. An endorsement is counted if it
+// endorses the commit vote on HashProposal and comes from a delegate, each delegate is counted once.
+func (b *Block) VerifyEndorsementQuorum(delegates []string, threshold float64) error {
+	if threshold < 0 || threshold >= 1 {
+		return errors.Errorf("invalid threshold %f", threshold)
+	}
+	pending := make(map[string]struct{}, len(delegates))
+	for _, d := range delegates {
+		pending[d] = struct{}{}
+	}
+	if len(pending) == 0 {
+		return errors.Wrap(ErrInsufficientEndorsements, "no delegate")
+	}
+	// the margin keeps e.g. 2.0/3 of 6 delegates from rounding below 4
+	required := int(math.Floor(threshold*float64(len(pending))+1e-9)) + 1
+	var (
+		vote  = commitVote(b.HashProposal())
+		count int
+	)
+	for _, en := range b.endorsements {
+		if en == nil || en.Endorser() == nil {
+			continue
+		}
+		endorser := en.Endorser().Address()
+		if endorser == nil {
+			continue
+		}
+		if _, ok := pending[endorser.String()]; !ok || !endorsement.VerifyEndorsement(vote, en) {
+			continue
+		}
+		delete(pending, endorser.String())
+		count++
+	}
+	if count < required {
+		return errors.Wrapf(ErrInsufficientEndorsements, "%d valid endorsements, %d required", count, required)
+	}
+	return nil
+}
+
+// CommitTimestamp returns the time the block was committed, as recorded in the footer
+func (b *Block) CommitTimestamp() time.Time {
+	return b.commitTime
+}
+
+// Endorsements returns a copy of the commit endorsements recorded in the footer
+func (b *Block) Endorsements() []*endorsement.Endorsement {
+	if b.endorsements == nil {
+		return nil
+	}
+	ens := make([]*endorsement.Endorsement, len(b.endorsements))
+	for i, en := range b.endorsements {
+		ens[i] = endorsement.NewEndorsement(en.Timestamp(), en.Endorser(), en.Signature())
+	}
+	return ens
+}
+
 // TransactionLog returns transaction logs in the block
 func (b *Block) TransactionLog() *BlkTransactionLog {
 	if len(b.Receipts) == 0 {
@@ -132,6 +810,761 @@ func (b *Block) TransactionLog() *BlkTransactionLog {
 	return &blkLog
 }
 
+// Equal returns true if the two blocks have the same header, actions in the same order, footer and receipts.
+// Memoized values are not compared, and nil receipts are different from empty receipts.
+func (b *Block) Equal(other *Block) bool {
+	if b == nil || other == nil {
+		return b == other
+	}
+	if !b.Header.Equal(&other.Header) || len(b.Actions) != len(other.Actions) {
+		return false
+	}
+	for i := range b.Actions {
+		h1, err := b.Actions[i].Hash()
+		if err != nil {
+			return false
+		}
+		h2, err := other.Actions[i].Hash()
+		if err != nil || h1 != h2 {
+			return false
+		}
+	}
+	f1, err := b.Footer.Serialize()
+	if err != nil {
+		return false
+	}
+	f2, err := other.Footer.Serialize()
+	if err != nil || !bytes.Equal(f1, f2) {
+		return false
+	}
+	if (b.Receipts == nil) != (other.Receipts == nil) || len(b.Receipts) != len(other.Receipts) {
+		return false
+	}
+	for i := range b.Receipts {
+		if b.Receipts[i].Hash() != other.Receipts[i].Hash() {
+			return false
+		}
+	}
+	return true
+}
+
+// SetReceipts replaces the receipts of the block and drops the cached receipt index, so it is rebuilt
+// on next use. Use it rather than assigning Receipts, especially when the receipts were changed in place.
+func (b *Block) SetReceipts(receipts []*action.Receipt) error {
+	if b.Sealed() {
+		return ErrBlockSealed
+	}
+	b.Receipts = receipts
+	b.receiptIdx = atomic.Value{}
+	return nil
+}
+
+// Seal marks the block as immutable, after which Finalize, AddEndorsement and SetReceipts return
+// ErrBlockSealed. It guards the mutating helpers only, the exported fields must still not be modified.
+// A clone of a sealed block is not sealed.
+func (b *Block) Seal() {
+	atomic.StoreInt32(&b.sealed, 1)
+}
+
+// Sealed returns true if the block has been sealed
+func (b *Block) Sealed() bool {
+	return atomic.LoadInt32(&b.sealed) == 1
+}
+
+// TxLogIndexMap returns, keyed by action hash, the index of the action's receipt in the block
+// and the index of the first log emitted by the action among all logs in the block.
+// The maps are cached along with the receipts and must not be modified.
+func (b *Block) TxLogIndexMap() (map[hash.Hash256]uint32, map[hash.Hash256]uint32) {
+	idx := b.indexReceipts()
+	return idx.txIndex, idx.logIndex
+}
+
+// AllLogs returns the logs of all receipts in the block, in order. Each log is a copy with its Index set to
+// its index among all logs in the block, and its TxIndex and ActionHash set from its receipt, consistent with
+// TxLogIndexMap. The topics and data are shared with the receipts and must not be modified.
+func (b *Block) AllLogs() ([]*action.Log, error) {
+	if b.Receipts == nil && len(b.Actions) > 0 {
+		return nil, ErrNoReceipts
+	}
+	var logs []*action.Log
+	for i, r := range b.Receipts {
+		if r == nil {
+			continue
+		}
+		for _, l := range r.Logs() {
+			cp := *l
+			cp.Index = uint32(len(logs))
+			cp.TxIndex = uint32(i)
+			cp.ActionHash = r.ActionHash
+			logs = append(logs, &cp)
+		}
+	}
+	return logs, nil
+}
+
+// ReceiptForAction returns the receipt of the given action, or false if the block has no receipt for it.
+// If more than one receipt carries the action hash, the first one in the block is returned.
+func (b *Block) ReceiptForAction(h hash.Hash256) (*action.Receipt, bool) {
+	r, ok := b.indexReceipts().byAction[h]
+	return r, ok
+}
+
+// VerifyReceiptsMatchActions verifies every receipt belongs to an action in the block, and every action has
+// exactly one receipt. An action included n times in the block must have n receipts.
+func (b *Block) VerifyReceiptsMatchActions() error {
+	hashes := make([]hash.Hash256, len(b.Actions))
+	expected := make(map[hash.Hash256]int, len(b.Actions))
+	for i := range b.Actions {
+		h, err := b.Actions[i].Hash()
+		if err != nil {
+			return errors.Wrapf(err, "failed to hash action %d", i)
+		}
+		hashes[i] = h
+		expected[h]++
+	}
+	found := make(map[hash.Hash256]int, len(b.Receipts))
+	for i, r := range b.Receipts {
+		if r == nil {
+			return errors.Wrapf(ErrReceiptMismatch, "receipt %d is nil", i)
+		}
+		if _, ok := expected[r.ActionHash]; !ok {
+			return errors.Wrapf(ErrReceiptMismatch, "receipt %d of action %x is orphaned", i, r.ActionHash)
+		}
+		found[r.ActionHash]++
+		if found[r.ActionHash] > expected[r.ActionHash] {
+			return errors.Wrapf(ErrReceiptMismatch, "receipt %d of action %x is a duplicate", i, r.ActionHash)
+		}
+	}
+	for i, h := range hashes {
+		if found[h] < expected[h] {
+			return errors.Wrapf(ErrReceiptMismatch, "action %d (%x) has no receipt", i, h)
+		}
+	}
+	return nil
+}
+
+func (b *Block) indexReceipts() *receiptIndex {
+	if idx, ok := b.receiptIdx.Load().(*receiptIndex); ok && idx.builtFrom(b.Receipts) {
+		return idx
+	}
+	idx := newReceiptIndex(b.Receipts)
+	b.receiptIdx.Store(idx)
+	return idx
+}
+
+// ActionByIndex returns the i-th action in the block, or ErrActionIndexOutOfRange
+func (b *Block) ActionByIndex(i uint32) (action.SealedEnvelope, error) {
+	if uint64(i) >= uint64(len(b.Actions)) {
+		return action.SealedEnvelope{}, errors.Wrapf(ErrActionIndexOutOfRange, "index %d, block has %d actions", i, len(b.Actions))
+	}
+	return b.Actions[i], nil
+}
+
+// ActionIndex returns the position of the action in the block, or false if the block does not contain it.
+// If the action appears more than once, the first position is returned. The index is cached and rebuilt
+// when Actions is replaced.
+func (b *Block) ActionIndex(h hash.Hash256) (uint32, bool) {
+	i, ok := b.indexActions().byAction[h]
+	return i, ok
+}
+
+func (b *Block) indexActions() *actionIndex {
+	if idx, ok := b.actionIdx.Load().(*actionIndex); ok && idx.builtFrom(b.Actions) {
+		return idx
+	}
+	idx := newActionIndex(b.Actions)
+	b.actionIdx.Store(idx)
+	return idx
+}
+
+// ActionCountBySender returns the number of actions of each sender in the block. The senders are derived from
+// the public keys of the actions and cached, until Actions is replaced. Since addresses are pointers, each
+// sender is keyed by a single address instance, iterate the map rather than looking up another instance.
+func (b *Block) ActionCountBySender() (map[address.Address]int, error) {
+	idx, err := b.indexSenders()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[address.Address]int)
+	for _, sender := range idx.senders {
+		counts[sender]++
+	}
+	return counts, nil
+}
+
+func (b *Block) indexSenders() (*senderIndex, error) {
+	if idx, ok := b.senderIdx.Load().(*senderIndex); ok && idx.builtFrom(b.Actions) {
+		return idx, nil
+	}
+	idx, err := newSenderIndex(b.Actions)
+	if err != nil {
+		return nil, err
+	}
+	b.senderIdx.Store(idx)
+	return idx, nil
+}
+
+// CheckNoDuplicateActions returns ErrDuplicateAction if an action appears more than once in the block
+func (b *Block) CheckNoDuplicateActions() error {
+	seen := make(map[string]int, len(b.Actions))
+	for i, h := range b.ActionHashs() {
+		if h == "" {
+			// the action cannot be hashed, which is reported by VerifyTxRoot
+			continue
+		}
+		if j, ok := seen[h]; ok {
+			return errors.Wrapf(ErrDuplicateAction, "action %s at index %d and %d", h, j, i)
+		}
+		seen[h] = i
+	}
+	return nil
+}
+
+// NumActions returns the number of actions in the block
+func (b *Block) NumActions() int {
+	return len(b.Actions)
+}
+
+// NumReceipts returns the number of receipts in the block
+func (b *Block) NumReceipts() int {
+	return len(b.Receipts)
+}
+
+// NumLogs returns the number of logs emitted by all receipts in the block, counted the same way as
+// the log indexes in TxLogIndexMap
+func (b *Block) NumLogs() int {
+	var n int
+	for _, r := range b.Receipts {
+		if r != nil {
+			n += len(r.Logs())
+		}
+	}
+	return n
+}
+
+// TotalGasConsumed returns the gas consumed by all receipts in the block, saturating at math.MaxUint64
+func (b *Block) TotalGasConsumed() uint64 {
+	return sumGasConsumed(b.Receipts, func(*action.Receipt) bool { return true })
+}
+
+// CumulativeGasUsed returns, keyed by action hash, the gas consumed by the actions of the block up to and
+// including the action, in the order of ActionHashs, saturating at math.MaxUint64 as TotalGasConsumed. Every
+// action must have a receipt, and appear once in the block.
+func (b *Block) CumulativeGasUsed() (map[hash.Hash256]uint64, error) {
+	var (
+		cumulative = make(map[hash.Hash256]uint64, len(b.Actions))
+		total      uint64
+	)
+	for i := range b.Actions {
+		h, err := b.Actions[i].Hash()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash action %d", i)
+		}
+		if _, ok := cumulative[h]; ok {
+			return nil, errors.Wrapf(ErrDuplicateAction, "action %x at index %d", h, i)
+		}
+		r, ok := b.ReceiptForAction(h)
+		if !ok {
+			return nil, errors.Wrapf(ErrReceiptMismatch, "action %d (%x) has no receipt", i, h)
+		}
+		if total > math.MaxUint64-r.GasConsumed {
+			total = math.MaxUint64
+		} else {
+			total += r.GasConsumed
+		}
+		cumulative[h] = total
+	}
+	return cumulative, nil
+}
+
+// TotalGasUsedByStatus returns the gas consumed by receipts with the given status (one of the
+// action.ReceiptStatus constants), saturating at math.MaxUint64
+func (b *Block) TotalGasUsedByStatus(status uint64) uint64 {
+	return sumGasConsumed(b.Receipts, func(r *action.Receipt) bool { return r.Status == status })
+}
+
+func sumGasConsumed(receipts []*action.Receipt, filter func(*action.Receipt) bool) uint64 {
+	var total uint64
+	for _, r := range receipts {
+		if r == nil || !filter(r) {
+			continue
+		}
+		if total > math.MaxUint64-r.GasConsumed {
+			return math.MaxUint64
+		}
+		total += r.GasConsumed
+	}
+	return total
+}
+
+// VerifyGasLimit returns ErrBlockGasExceeded if the gas of the block is above blockGasLimit. If the block has
+// receipts, the gas is the gas consumed by the receipts, as TotalGasConsumed; otherwise it is the sum of the gas
+// limits declared by the actions, which is an upper bound of the gas the block may consume.
+func (b *Block) VerifyGasLimit(blockGasLimit uint64) error {
+	basis, total := "consumed", b.TotalGasConsumed()
+	if len(b.Receipts) == 0 {
+		basis, total = "declared", 0
+		for i := range b.Actions {
+			if b.Actions[i].Envelope == nil {
+				return errors.Errorf("action %d has no envelope", i)
+			}
+			gasLimit := b.Actions[i].GasLimit()
+			if total > math.MaxUint64-gasLimit {
+				total = math.MaxUint64
+				break
+			}
+			total += gasLimit
+		}
+	}
+	if total > blockGasLimit {
+		return errors.Wrapf(ErrBlockGasExceeded, "%s gas %d, limit %d", basis, total, blockGasLimit)
+	}
+	return nil
+}
+
+// VerifyMinGasPrice returns ErrGasPriceTooLow for the first action whose gas price is below floor, a missing gas
+// price counts as zero. It is a policy check of the node, which consensus does not require.
+func (b *Block) VerifyMinGasPrice(floor *big.Int) error {
+	if floor == nil || floor.Sign() <= 0 {
+		return nil
+	}
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return errors.Errorf("action %d has no envelope", i)
+		}
+		price := b.Actions[i].GasPrice()
+		if price == nil {
+			price = big.NewInt(0)
+		}
+		if price.Cmp(floor) < 0 {
+			return errors.Wrapf(ErrGasPriceTooLow, "action %d has gas price %s, floor %s", i, price, floor)
+		}
+	}
+	return nil
+}
+
+// TotalTransferAmount returns the total value moved by the block, which is the sum of the amounts of transfers
+// and of the value attached to executions. Unlike CalculateTransferAmount, executions are included.
+func (b *Block) TotalTransferAmount() (*big.Int, error) {
+	total := big.NewInt(0)
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		var amount *big.Int
+		switch act := b.Actions[i].Action().(type) {
+		case *action.Transfer:
+			amount = act.Amount()
+		case *action.Execution:
+			amount = act.Amount()
+		}
+		if amount != nil {
+			total.Add(total, amount)
+		}
+	}
+	return total, nil
+}
+
+// HasExecutions returns true if the block contains any contract execution
+func (b *Block) HasExecutions() bool {
+	return b.ExecutionCount() > 0
+}
+
+// ExecutionCount returns the number of contract executions in the block, including deployments. It only
+// looks at the type of the actions, no signer is recovered.
+func (b *Block) ExecutionCount() int {
+	var count int
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			continue
+		}
+		if _, ok := b.Actions[i].Action().(*action.Execution); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// EstimatedStateDelta returns a heuristic estimate of how much the block grows the state, in bytes. Transfers,
+// executions and contract deployments, which also count the size of their code, add a constant each, and
+// every log in the receipts adds StateDeltaPerLog. Other actions are not counted.
+func (b *Block) EstimatedStateDelta() (int64, error) {
+	var delta int64
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return 0, errors.Errorf("action %d has no envelope", i)
+		}
+		switch act := b.Actions[i].Action().(type) {
+		case *action.Transfer:
+			delta += StateDeltaPerTransfer
+		case *action.Execution:
+			if act.Contract() == action.EmptyAddress {
+				delta += StateDeltaPerDeployment + int64(len(act.Data()))
+			} else {
+				delta += StateDeltaPerExecution
+			}
+		}
+	}
+	for _, r := range b.Receipts {
+		if r != nil {
+			delta += StateDeltaPerLog * int64(len(r.Logs()))
+		}
+	}
+	return delta, nil
+}
+
+// DataGas returns the gas charged for the data carried by the actions of the block, which are the payload of
+// transfers and the data of executions, priced at DataGasPerZeroByte and DataGasPerNonZeroByte. Other actions
+// are not counted.
+func (b *Block) DataGas() (uint64, error) {
+	var zeros, nonZeros uint64
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return 0, errors.Errorf("action %d has no envelope", i)
+		}
+		var data []byte
+		switch act := b.Actions[i].Action().(type) {
+		case *action.Transfer:
+			data = act.Payload()
+		case *action.Execution:
+			data = act.Data()
+		}
+		for _, c := range data {
+			if c == 0 {
+				zeros++
+			} else {
+				nonZeros++
+			}
+		}
+	}
+	var gas uint64
+	for _, c := range []struct{ bytes, cost uint64 }{
+		{zeros, DataGasPerZeroByte},
+		{nonZeros, DataGasPerNonZeroByte},
+	} {
+		hi, lo := bits.Mul64(c.bytes, c.cost)
+		sum, carry := bits.Add64(gas, lo, 0)
+		if hi != 0 || carry != 0 {
+			return 0, errors.Errorf("data gas of %d zero and %d non-zero bytes overflows", zeros, nonZeros)
+		}
+		gas = sum
+	}
+	return gas, nil
+}
+
+// TouchedAddresses returns the unique addresses touched by the block, sorted by their string form.
+// It covers the sender and recipient of every action and the emitter of every log in the receipts.
+// Actions are signed consensus data, so a malformed recipient is an error; receipts are produced
+// locally and may carry placeholder log addresses, so a log address that cannot be parsed is skipped.
+func (b *Block) TouchedAddresses() ([]address.Address, error) {
+	touched := make(map[string]address.Address)
+	for i := range b.Actions {
+		selp := &b.Actions[i]
+		if pk := selp.SrcPubkey(); pk != nil {
+			if sender := pk.Address(); sender != nil {
+				touched[sender.String()] = sender
+			}
+		}
+		dst, ok := selp.Destination()
+		if !ok || dst == "" {
+			// contract deployment or an action without recipient
+			continue
+		}
+		addr, err := address.FromString(dst)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid recipient %s of action %d", dst, i)
+		}
+		touched[addr.String()] = addr
+	}
+	for _, r := range b.Receipts {
+		if r == nil {
+			continue
+		}
+		for _, l := range r.Logs() {
+			addr, err := address.FromString(l.Address)
+			if err != nil {
+				log.L().Debug("Skipping malformed log address", zap.String("address", l.Address), zap.Error(err))
+				continue
+			}
+			touched[addr.String()] = addr
+		}
+	}
+	keys := make([]string, 0, len(touched))
+	for k := range touched {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	addrs := make([]address.Address, len(keys))
+	for i, k := range keys {
+		addrs[i] = touched[k]
+	}
+	return addrs, nil
+}
+
+// SenderNonceRanges returns the lowest and highest nonce of the actions of each sender in the block. The senders
+// are derived from the public keys of the actions like ActionCountBySender, no signature is recovered. Since
+// addresses are pointers, each sender is keyed by a single address instance, iterate the map rather than looking
+// up another instance.
+func (b *Block) SenderNonceRanges() (map[address.Address][2]uint64, error) {
+	idx, err := b.indexSenders()
+	if err != nil {
+		return nil, err
+	}
+	ranges := make(map[address.Address][2]uint64)
+	for i, sender := range idx.senders {
+		selp := &b.Actions[i]
+		if selp.Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		nonce := selp.Nonce()
+		r, ok := ranges[sender]
+		switch {
+		case !ok:
+			r = [2]uint64{nonce, nonce}
+		case nonce < r[0]:
+			r[0] = nonce
+		case nonce > r[1]:
+			r[1] = nonce
+		}
+		ranges[sender] = r
+	}
+	return ranges, nil
+}
+
+// VerifyNonceContinuity verifies the actions of each sender continue the nonce sequence of the sender, which is
+// the nonce its next action must have given the state before the block: the lowest nonce of the sender in the
+// block is the one in accountNonces, and its other nonces follow without gap or duplicate. accountNonces is
+// matched by address string, a sender missing from it is an error. The senders are derived like
+// ActionCountBySender.
+func (b *Block) VerifyNonceContinuity(accountNonces map[address.Address]uint64) error {
+	expected := make(map[string]uint64, len(accountNonces))
+	for addr, nonce := range accountNonces {
+		if addr != nil {
+			expected[addr.String()] = nonce
+		}
+	}
+	idx, err := b.indexSenders()
+	if err != nil {
+		return err
+	}
+	nonces := make(map[string][]uint64)
+	for i, sender := range idx.senders {
+		selp := &b.Actions[i]
+		if selp.Envelope == nil {
+			return errors.Errorf("action %d has no envelope", i)
+		}
+		nonces[sender.String()] = append(nonces[sender.String()], selp.Nonce())
+	}
+	senders := make([]string, 0, len(nonces))
+	for sender := range nonces {
+		senders = append(senders, sender)
+	}
+	// report the same sender whatever the map order
+	sort.Strings(senders)
+	for _, sender := range senders {
+		next, ok := expected[sender]
+		if !ok {
+			return errors.Errorf("no account nonce for sender %s", sender)
+		}
+		ns := nonces[sender]
+		sort.Slice(ns, func(i, j int) bool { return ns[i] < ns[j] })
+		for _, n := range ns {
+			if n != next {
+				return errors.Wrapf(ErrNonceGap, "sender %s has nonce %d, expecting %d", sender, n, next)
+			}
+			next++
+		}
+	}
+	return nil
+}
+
+// GasPrices returns the gas price of each action in the block, in the same order as ActionHashs.
+// A nil gas price is returned as zero, and every returned value is a copy.
+func (b *Block) GasPrices() ([]*big.Int, error) {
+	prices := make([]*big.Int, len(b.Actions))
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		prices[i] = b.Actions[i].GasPrice()
+	}
+	return prices, nil
+}
+
+// WeightedMedianGasPrice returns the median of the gas prices of the actions weighted by their gas limits, that
+// is the lowest price such that the actions at or below it hold at least half of the total gas limit. A nil gas
+// price counts as zero. It returns zero for a block without actions or whose actions have no gas limit.
+func (b *Block) WeightedMedianGasPrice() (*big.Int, error) {
+	prices, err := b.GasPrices()
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(prices))
+	total := new(big.Int)
+	for i := range prices {
+		order[i] = i
+		total.Add(total, new(big.Int).SetUint64(b.Actions[i].GasLimit()))
+	}
+	if total.Sign() == 0 {
+		return big.NewInt(0), nil
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return prices[order[i]].Cmp(prices[order[j]]) < 0
+	})
+	var (
+		half = new(big.Int).Rsh(new(big.Int).Add(total, big.NewInt(1)), 1)
+		cum  = new(big.Int)
+	)
+	for _, i := range order {
+		cum.Add(cum, new(big.Int).SetUint64(b.Actions[i].GasLimit()))
+		if cum.Cmp(half) >= 0 {
+			return prices[i], nil
+		}
+	}
+	return prices[order[len(order)-1]], nil
+}
+
+// Bits of ActionTypeMask, one per action type
+const (
+	ActionTypeTransfer uint64 = 1 << iota
+	ActionTypeExecution
+	ActionTypeDepositToRewardingFund
+	ActionTypeClaimFromRewardingFund
+	ActionTypeGrantReward
+	ActionTypeStakeCreate
+	ActionTypeStakeUnstake
+	ActionTypeStakeWithdraw
+	ActionTypeStakeAddDeposit
+	ActionTypeStakeRestake
+	ActionTypeStakeChangeCandidate
+	ActionTypeStakeTransferOwnership
+	ActionTypeCandidateRegister
+	ActionTypeCandidateUpdate
+	ActionTypePutPollResult
+
+	// ActionTypeOther is set for an action of a type without its own bit, e.g. a type added after this one
+	ActionTypeOther uint64 = 1 << 63
+)
+
+// actionTypeBits maps the type names of ActionsOfType to their bit in ActionTypeMask
+var actionTypeBits = map[string]uint64{
+	"transfer":               ActionTypeTransfer,
+	"execution":              ActionTypeExecution,
+	"depositToRewardingFund": ActionTypeDepositToRewardingFund,
+	"claimFromRewardingFund": ActionTypeClaimFromRewardingFund,
+	"grantReward":            ActionTypeGrantReward,
+	"stakeCreate":            ActionTypeStakeCreate,
+	"stakeUnstake":           ActionTypeStakeUnstake,
+	"stakeWithdraw":          ActionTypeStakeWithdraw,
+	"stakeAddDeposit":        ActionTypeStakeAddDeposit,
+	"stakeRestake":           ActionTypeStakeRestake,
+	"stakeChangeCandidate":   ActionTypeStakeChangeCandidate,
+	"stakeTransferOwnership": ActionTypeStakeTransferOwnership,
+	"candidateRegister":      ActionTypeCandidateRegister,
+	"candidateUpdate":        ActionTypeCandidateUpdate,
+	"putPollResult":          ActionTypePutPollResult,
+}
+
+// ActionTypeMask returns the set of the types of the actions in the block, as the bitwise OR of the ActionType
+// bits. An action without envelope or of a type without its own bit sets ActionTypeOther.
+func (b *Block) ActionTypeMask() uint64 {
+	var mask uint64
+	for _, selp := range b.Actions {
+		if selp.Envelope == nil {
+			mask |= ActionTypeOther
+			continue
+		}
+		if bit, ok := actionTypeBits[actionTypeName(selp.Envelope)]; ok {
+			mask |= bit
+		} else {
+			mask |= ActionTypeOther
+		}
+	}
+	return mask
+}
+
+// ActionsOfType returns the actions of the given type in block order. The type is the name of the action
+// field in the ActionCore protobuf message, e.g. "transfer", "execution" or "grantReward". Actions without an
+// envelope have no type and are skipped.
+func (b *Block) ActionsOfType(typeName string) []action.SealedEnvelope {
+	acts := []action.SealedEnvelope{}
+	for _, selp := range b.Actions {
+		if selp.Envelope == nil {
+			continue
+		}
+		if actionTypeName(selp.Envelope) == typeName {
+			acts = append(acts, selp)
+		}
+	}
+	return acts
+}
+
+// actionTypeName returns the name of the action field set in the ActionCore protobuf message of elp
+func actionTypeName(elp action.Envelope) string {
+	return actionCoreTypeName(elp.Proto())
+}
+
+func actionCoreTypeName(pb *iotextypes.ActionCore) string {
+	core := pb.ProtoReflect()
+	fd := core.WhichOneof(core.Descriptor().Oneofs().ByName("action"))
+	if fd == nil {
+		return ""
+	}
+	return string(fd.Name())
+}
+
+// SizeBreakdown returns the number of bytes taken by the parts of the serialized block, keyed by "header",
+// "footer" and the type name of actions as in ActionsOfType. The bytes of the format version, and of the
+// tags and lengths framing the header, the body, the footer and each action are counted under "framing", so
+// the values sum up to the length returned by Serialize.
+func (b *Block) SizeBreakdown() (map[string]int, error) {
+	ser, err := b.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	pb := b.ConvertToBlockPb()
+	sizes := map[string]int{
+		"header": proto.Size(pb.GetHeader()),
+		"footer": proto.Size(pb.GetFooter()),
+	}
+	counted := sizes["header"] + sizes["footer"]
+	for _, act := range pb.GetBody().GetActions() {
+		name := actionCoreTypeName(act.GetCore())
+		if name == "" {
+			name = "unknown"
+		}
+		size := proto.Size(act)
+		sizes[name] += size
+		counted += size
+	}
+	sizes["framing"] = len(ser) - counted
+	return sizes, nil
+}
+
+// ActionSetFingerprint returns the hash of the concatenated action hashes sorted in ascending order, so blocks
+// with the same actions have the same fingerprint whatever the order of the actions, unlike the tx root and
+// RunnableActions.Hash. An action included twice counts twice. Like ActionHashs, an action which fails to hash
+// is skipped.
+func (b *Block) ActionSetFingerprint() hash.Hash256 {
+	hashes := make([]hash.Hash256, 0, len(b.Actions))
+	for i := range b.Actions {
+		h, err := b.Actions[i].Hash()
+		if err != nil {
+			log.L().Debug("Skipping action due to hash error", zap.Error(err))
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	buf := make([]byte, 0, len(hashes)*len(hash.ZeroHash256))
+	for _, h := range hashes {
+		buf = append(buf, h[:]...)
+	}
+	return hash.Hash256b(buf)
+}
+
 // ActionHashs returns action hashs in the block
 func (b *Block) ActionHashs() []string {
 	actHash := make([]string, len(b.Actions))
@@ -145,3 +1578,20 @@ func (b *Block) ActionHashs() []string {
 	}
 	return actHash
 }
+
+// ActionSizes returns the serialized size of each action in bytes, keyed by action hash. The sizes
+// add up to the body size minus the per-action protobuf framing.
+func (b *Block) ActionSizes() (map[hash.Hash256]int, error) {
+	sizes := make(map[hash.Hash256]int, len(b.Actions))
+	for i := range b.Actions {
+		if b.Actions[i].Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		h, err := b.Actions[i].Hash()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash action %d", i)
+		}
+		sizes[h] = proto.Size(b.Actions[i].Proto())
+	}
+	return sizes, nil
+}
@@ -33,6 +33,9 @@ func (bd *Deserializer) FromBlockProto(pbBlock *iotextypes.Block) (*Block, error
 
 // DeserializeBlock de-serializes a block
 func (bd *Deserializer) DeserializeBlock(buf []byte) (*Block, error) {
+	if err := verifyActionCount(buf, true); err != nil {
+		return nil, err
+	}
 	pbBlock := iotextypes.Block{}
 	if err := proto.Unmarshal(buf, &pbBlock); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal block")
@@ -59,6 +62,9 @@ func (bd *Deserializer) FromBodyProto(pbBody *iotextypes.BlockBody) (*Body, erro
 
 // DeserializeBody de-serializes a block body
 func (bd *Deserializer) DeserializeBody(buf []byte) (*Body, error) {
+	if err := verifyActionCount(buf, false); err != nil {
+		return nil, err
+	}
 	pb := iotextypes.BlockBody{}
 	if err := proto.Unmarshal(buf, &pb); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal block body")
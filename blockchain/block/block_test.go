@@ -7,19 +7,31 @@
 package block
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/iotexproject/go-pkgs/bloom"
 	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-address/address"
 	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/endorsement"
 	"github.com/iotexproject/iotex-core/pkg/compress"
 	"github.com/iotexproject/iotex-core/pkg/log"
 	"github.com/iotexproject/iotex-core/pkg/unit"
@@ -62,86 +74,2076 @@ func TestMerkle(t *testing.T) {
 		producerPubKey,
 		actions,
 	)
-	hash, err := block.CalculateTxRoot()
+	txRoot, err := block.CalculateTxRoot()
 	require.NoError(err)
-	require.Equal("eb5cb75ae199d96de7c1cd726d5e1a3dff15022ed7bdc914a3d8b346f1ef89c9", hex.EncodeToString(hash[:]))
+	require.Equal("eb5cb75ae199d96de7c1cd726d5e1a3dff15022ed7bdc914a3d8b346f1ef89c9", hex.EncodeToString(txRoot[:]))
+	actionHashes := make([]hash.Hash256, 0, len(actions))
+	for _, selp := range actions {
+		h, err := selp.Hash()
+		require.NoError(err)
+		actionHashes = append(actionHashes, h)
+	}
+	rootFromHashes, err := CalculateTxRootFromHashes(actionHashes)
+	require.NoError(err)
+	require.Equal(txRoot, rootFromHashes)
+	rootWith, err := block.CalculateTxRootWith(hash.Hash256b)
+	require.NoError(err)
+	require.Equal(txRoot, rootWith)
+	// keeping the right half of each node reduces the tree to its last leaf
+	rootWith, err = block.CalculateTxRootWith(hash.BytesToHash256)
+	require.NoError(err)
+	lastHash, err := selp4.Hash()
+	require.NoError(err)
+	require.Equal(lastHash, rootWith)
+
+	// excluding an action is the same as building the block without it
+	excludedRoot, excluded, err := block.TxRootExcluding(actionHashes[2])
+	require.NoError(err)
+	require.True(excluded)
+	require.NotEqual(txRoot, excludedRoot)
+	expected, err := calculateTxRoot([]action.SealedEnvelope{selp0, selp1, selp3, selp4})
+	require.NoError(err)
+	require.Equal(expected, excludedRoot)
+	stable, _, err := block.TxRootExcluding(actionHashes[2])
+	require.NoError(err)
+	require.Equal(excludedRoot, stable)
+	unchangedRoot, excluded, err := block.TxRootExcluding(hash.Hash256b([]byte("not included")))
+	require.NoError(err)
+	require.False(excluded)
+	require.Equal(txRoot, unchangedRoot)
+
+	hashes := block.ActionHashs()
+	for i := range hashes {
+		h, err := actions[i].Hash()
+		require.NoError(err)
+		require.Equal(hex.EncodeToString(h[:]), hashes[i])
+	}
+
+	// receipts are not populated yet
+	h0, err := selp0.Hash()
+	require.NoError(err)
+	r, ok := block.ReceiptForAction(h0)
+	require.False(ok)
+	require.Nil(r)
+
+	// selp1 has two receipts and selp4 has none
+	block.Receipts = make([]*action.Receipt, 0, 5)
+	for i, n := range []struct {
+		selp    action.SealedEnvelope
+		numLogs int
+	}{
+		{selp0, 2}, {selp1, 1}, {selp1, 3}, {selp3, 0}, {selp2, 1},
+	} {
+		h, err := n.selp.Hash()
+		require.NoError(err)
+		receipt := &action.Receipt{ActionHash: h, GasConsumed: uint64(i)}
+		for j := 0; j < n.numLogs; j++ {
+			receipt.AddLogs(&action.Log{Address: "1", ActionHash: h})
+		}
+		block.Receipts = append(block.Receipts, receipt)
+	}
+	require.Equal(ErrReceiptMismatch, errors.Cause(block.VerifyReceiptsMatchActions()))
+	txIndex, logIndex := block.TxLogIndexMap()
+	require.Equal(4, len(txIndex))
+	require.Equal(4, len(logIndex))
+	for i, expect := range []struct {
+		selp     action.SealedEnvelope
+		txIndex  uint32
+		logIndex uint32
+	}{
+		{selp0, 0, 0}, {selp1, 1, 2}, {selp3, 3, 6}, {selp2, 4, 6},
+	} {
+		h, err := expect.selp.Hash()
+		require.NoError(err)
+		require.Equal(expect.txIndex, txIndex[h], i)
+		require.Equal(expect.logIndex, logIndex[h], i)
+		r, ok := block.ReceiptForAction(h)
+		require.True(ok)
+		require.Equal(block.Receipts[expect.txIndex], r)
+	}
+	// the first log of each action is at the log index of the action
+	logs, err := block.AllLogs()
+	require.NoError(err)
+	require.Len(logs, 7)
+	for i, l := range logs {
+		require.Equal(uint32(i), l.Index)
+		require.Equal(block.Receipts[l.TxIndex].ActionHash, l.ActionHash)
+	}
+	for _, selp := range []action.SealedEnvelope{selp0, selp1, selp2} {
+		h, err := selp.Hash()
+		require.NoError(err)
+		require.Equal(h, logs[logIndex[h]].ActionHash)
+		require.Equal(txIndex[h], logs[logIndex[h]].TxIndex)
+	}
+	// the logs of the receipts are not modified
+	require.Zero(block.Receipts[4].Logs()[0].Index)
+	data, err := block.MarshalLogIndex()
+	require.NoError(err)
+	again, err := block.MarshalLogIndex()
+	require.NoError(err)
+	require.Equal(data, again)
+	txIndex2, logIndex2, err := UnmarshalLogIndex(data)
+	require.NoError(err)
+	require.Equal(txIndex, txIndex2)
+	require.Equal(logIndex, logIndex2)
+	// the first receipt of selp1 wins
+	h1, err := selp1.Hash()
+	require.NoError(err)
+	r, ok = block.ReceiptForAction(h1)
+	require.True(ok)
+	require.EqualValues(1, r.GasConsumed)
+	h4, err := selp4.Hash()
+	require.NoError(err)
+	_, ok = block.ReceiptForAction(h4)
+	require.False(ok)
+	require.Equal(5, block.NumActions())
+	require.Equal(5, block.NumReceipts())
+	require.Equal(7, block.NumLogs())
+
+	t.Log("Merkle root match pass\n")
+}
+
+var (
+	pkBytes = identityset.PrivateKey(27).PublicKey().Bytes()
+	pbBlock = iotextypes.Block{
+		Header: &iotextypes.BlockHeader{
+			Core: &iotextypes.BlockHeaderCore{
+				Version:   version.ProtocolVersion,
+				Height:    123456789,
+				Timestamp: timestamppb.Now(),
+			},
+			ProducerPubkey: pkBytes,
+		},
+		Body: &iotextypes.BlockBody{
+			Actions: []*iotextypes.Action{
+				{
+					Core: &iotextypes.ActionCore{
+						Action: &iotextypes.ActionCore_Transfer{
+							Transfer: &iotextypes.Transfer{
+								Amount:    "100000000000000000",
+								Recipient: "alice",
+							},
+						},
+						Version: version.ProtocolVersion,
+						Nonce:   101,
+						ChainID: 1,
+					},
+					SenderPubKey: pkBytes,
+					Signature:    action.ValidSig,
+				},
+				{
+					Core: &iotextypes.ActionCore{
+						Action: &iotextypes.ActionCore_Execution{
+							Execution: &iotextypes.Execution{
+								Contract: "bob",
+								Amount:   "200000000000000000",
+								Data:     []byte{1, 2, 3, 4},
+							},
+						},
+						Version: version.ProtocolVersion,
+						Nonce:   102,
+						ChainID: 2,
+					},
+					SenderPubKey: pkBytes,
+					Signature:    action.ValidSig,
+				},
+			},
+		},
+	}
+)
+
+func TestNewHeaderOnlyBlock(t *testing.T) {
+	require := require.New(t)
+
+	full := makeBlock(t, 3)
+	require.False(full.IsHeaderOnly())
+	blk := NewHeaderOnlyBlock(&full.Header)
+	require.True(blk.IsHeaderOnly())
+	require.NotNil(blk.Actions)
+	require.Nil(blk.Receipts)
+	require.Equal(full.HashBlock(), blk.HashBlock())
+	require.True(full.Header.Equal(&blk.Header))
+	ser, err := full.Header.Serialize()
+	require.NoError(err)
+	headerSer, err := blk.Header.Serialize()
+	require.NoError(err)
+	require.Equal(ser, headerSer)
+
+	// the body accessors see an empty body
+	require.Zero(blk.NumActions())
+	require.Zero(blk.NumReceipts())
+	require.Zero(blk.NumLogs())
+	require.Zero(blk.TotalGasConsumed())
+	require.Empty(blk.ActionHashs())
+	require.Zero(blk.ExecutionCount())
+	_, err = blk.ActionByIndex(0)
+	require.Equal(ErrActionIndexOutOfRange, errors.Cause(err))
+	amount, err := blk.TotalTransferAmount()
+	require.NoError(err)
+	require.Zero(amount.Sign())
+	txRoot, err := blk.CalculateTxRoot()
+	require.NoError(err)
+	require.Equal(hash.ZeroHash256, txRoot)
+	require.Equal(ErrTxRootMismatch, errors.Cause(blk.VerifyTxRoot()))
+	require.Equal(3, full.Summary().NumActions)
+	require.Zero(blk.Summary().NumActions)
+	_, err = blk.Serialize()
+	require.NoError(err)
+
+	// the header is copied
+	blk.extraData = append(blk.extraData, 1)
+	require.Empty(full.ExtraData())
+
+	// a block without action has an empty tx root
+	empty, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.False(NewHeaderOnlyBlock(&empty.Header).IsHeaderOnly())
+}
+
+func TestActionSetFingerprint(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 4)
+	fp := blk.ActionSetFingerprint()
+	require.NotEqual(hash.ZeroHash256, fp)
+	require.Equal(fp, blk.ActionSetFingerprint())
+
+	reordered := blk.Clone()
+	acts := reordered.Actions
+	acts[0], acts[1], acts[2], acts[3] = acts[3], acts[2], acts[0], acts[1]
+	require.NotEqual(blk.RunnableActions().Hash(), reordered.RunnableActions().Hash())
+	require.Equal(fp, reordered.ActionSetFingerprint())
+
+	// removing or duplicating an action changes the set
+	fewer := blk.Clone()
+	fewer.Actions = fewer.Actions[:3]
+	require.NotEqual(fp, fewer.ActionSetFingerprint())
+	more := blk.Clone()
+	more.Actions = append(more.Actions, more.Actions[0])
+	require.NotEqual(fp, more.ActionSetFingerprint())
+	require.NotEqual(fp, (&Block{}).ActionSetFingerprint())
+}
+
+func TestSerializeReceipts(t *testing.T) {
+	require := require.New(t)
+
+	producer := identityset.PrivateKey(27)
+	var acts []action.SealedEnvelope
+	for i := 28; i < 33; i++ {
+		selp, err := action.SignedTransfer(identityset.Address(i).String(), producer, 1, big.NewInt(50<<22), nil, 100, big.NewInt(0))
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	blk := &Block{Body: Body{Actions: acts}}
+	receipts := makeReceipts(t, blk, 2)
+	var logIndex uint32
+	for i, r := range receipts {
+		logIndex = r.UpdateIndex(uint32(i), logIndex)
+	}
+	receipts[1].SetExecutionRevertMsg("reverted")
+	require.NoError(blk.SetReceipts(receipts))
+	receiptRoot := func(receipts []*action.Receipt) hash.Hash256 {
+		leaves := make([]hash.Hash256, len(receipts))
+		for i, r := range receipts {
+			leaves[i] = r.Hash()
+		}
+		return crypto.NewMerkleTree(leaves).HashTree()
+	}
+
+	data, err := blk.SerializeReceipts()
+	require.NoError(err)
+	again, err := blk.SerializeReceipts()
+	require.NoError(err)
+	require.Equal(data, again)
+	decoded, err := DeserializeReceipts(data)
+	require.NoError(err)
+	require.Len(decoded, len(receipts))
+	for i, r := range decoded {
+		require.Equal(receipts[i].ActionHash, r.ActionHash)
+		require.Equal(receipts[i].GasConsumed, r.GasConsumed)
+		require.Equal(receipts[i].TxIndex, r.TxIndex)
+		require.Equal(receipts[i].ExecutionRevertMsg(), r.ExecutionRevertMsg())
+		require.Len(r.Logs(), 2)
+		for j, l := range r.Logs() {
+			require.Equal(receipts[i].Logs()[j].Index, l.Index)
+			require.Equal(receipts[i].Logs()[j].ActionHash, l.ActionHash)
+			require.Equal(receipts[i].Logs()[j].Topics, l.Topics)
+		}
+	}
+	require.EqualValues(9, decoded[4].Logs()[1].Index)
+	require.Equal(receiptRoot(receipts), receiptRoot(decoded))
+
+	// no receipt
+	data, err = (&Block{}).SerializeReceipts()
+	require.NoError(err)
+	decoded, err = DeserializeReceipts(data)
+	require.NoError(err)
+	require.Empty(decoded)
+
+	for _, data := range [][]byte{nil, {2}, {receiptsVersion1, 0xff}} {
+		_, err = DeserializeReceipts(data)
+		require.Equal(ErrInvalidReceipts, errors.Cause(err))
+	}
+	require.NoError(blk.SetReceipts([]*action.Receipt{nil}))
+	_, err = blk.SerializeReceipts()
+	require.Equal(ErrInvalidReceipts, errors.Cause(err))
+}
+
+func TestConvertFromBlockPb(t *testing.T) {
+	blk := Block{}
+	require.NoError(t, blk.ConvertFromBlockPb(&pbBlock))
+
+	txHash, err := blk.CalculateTxRoot()
+	require.NoError(t, err)
+
+	blk.Header.txRoot = txHash
+	blk.Header.receiptRoot = hash.Hash256b(([]byte)("test"))
+
+	raw, err := blk.Serialize()
+	require.NoError(t, err)
+
+	var newblk Block
+	err = newblk.Deserialize(raw)
+	require.NoError(t, err)
+	require.True(t, blk.Equal(&newblk))
+
+	// cached header hash equals the freshly computed one
+	for _, h := range []*Header{&blk.Header, &newblk.Header} {
+		ser, err := h.Serialize()
+		require.NoError(t, err)
+		require.Equal(t, hash.Hash256b(ser), h.HashHeader())
+		require.Equal(t, hash.Hash256b(ser), h.HashBlock())
+		streamed, err := h.HashStream()
+		require.NoError(t, err)
+		require.Equal(t, hash.Hash256b(ser), streamed)
+	}
+}
+
+func TestConvertFromBlockPbTooManyActions(t *testing.T) {
+	require := require.New(t)
+
+	pb := &iotextypes.Block{
+		Header: pbBlock.Header,
+		Body: &iotextypes.BlockBody{
+			Actions: make([]*iotextypes.Action, MaxActionsPerBlock+1),
+		},
+	}
+	for i := range pb.Body.Actions {
+		pb.Body.Actions[i] = &iotextypes.Action{}
+	}
+	blk := Block{}
+	require.Equal(ErrTooManyActions, errors.Cause(blk.ConvertFromBlockPb(pb)))
+
+	raw, err := proto.Marshal(pb)
+	require.NoError(err)
+	require.Equal(ErrTooManyActions, errors.Cause(blk.Deserialize(raw)))
+	_, err = (&Deserializer{}).DeserializeBlock(raw)
+	require.Equal(ErrTooManyActions, errors.Cause(err))
+
+	// the actions are counted on the wire before unmarshaling: each action below is malformed, so a full
+	// unmarshal would fail with a parse error instead
+	defer func(limit uint64) { MaxActionsPerBlock = limit }(MaxActionsPerBlock)
+	MaxActionsPerBlock = 3
+	craft := func(numActs int) ([]byte, []byte) {
+		var body []byte
+		for i := 0; i < numActs; i++ {
+			body = protowire.AppendTag(body, bodyActionsFieldNum, protowire.BytesType)
+			body = protowire.AppendBytes(body, []byte{0x0f})
+		}
+		raw := protowire.AppendTag(nil, blockBodyFieldNum, protowire.BytesType)
+		return protowire.AppendBytes(raw, body), body
+	}
+	raw, body := craft(4)
+	require.Error(proto.Unmarshal(raw, &iotextypes.Block{}))
+	require.Equal(ErrTooManyActions, errors.Cause(blk.DeserializeFromNetwork(raw)))
+	_, err = (&Deserializer{}).DeserializeBlock(raw)
+	require.Equal(ErrTooManyActions, errors.Cause(err))
+	require.Equal(ErrTooManyActions, errors.Cause((&Body{}).Deserialize(body)))
+	_, err = (&Deserializer{}).DeserializeBody(body)
+	require.Equal(ErrTooManyActions, errors.Cause(err))
+	// the body field split in two is merged by proto.Unmarshal, so both parts count
+	half, _ := craft(2)
+	require.Equal(ErrTooManyActions, errors.Cause(blk.DeserializeFromNetwork(append(half, half...))))
+
+	raw, body = craft(3)
+	err = blk.DeserializeFromNetwork(raw)
+	require.Error(err)
+	require.NotEqual(ErrTooManyActions, errors.Cause(err))
+	err = (&Body{}).Deserialize(body)
+	require.Error(err)
+	require.NotEqual(ErrTooManyActions, errors.Cause(err))
+}
+
+func TestBlockFooterAccessors(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 1)
+	commitTime := time.Unix(1600000000, 123).UTC()
+	en := endorsement.NewEndorsement(commitTime.Add(-time.Second), identityset.PrivateKey(28).PublicKey(), []byte{1, 2, 3})
+	require.NoError(blk.Finalize([]*endorsement.Endorsement{en}, commitTime))
+
+	raw, err := blk.Serialize()
+	require.NoError(err)
+	var newblk Block
+	require.NoError(newblk.Deserialize(raw))
+	require.True(commitTime.Equal(newblk.CommitTimestamp()))
+	ens := newblk.Endorsements()
+	require.Equal(1, len(ens))
+	require.True(en.Timestamp().Equal(ens[0].Timestamp()))
+	require.Equal(en.Endorser().Bytes(), ens[0].Endorser().Bytes())
+	require.Equal(en.Signature(), ens[0].Signature())
+
+	// mutating the returned slice does not affect the block
+	ens[0] = nil
+	require.NotNil(newblk.Endorsements()[0])
+	require.Nil(NewTestingBuilder().blk.Endorsements())
+}
+
+func TestVerifyProducerIn(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 1)
+	delegates := map[string]struct{}{
+		identityset.Address(0).String(): {},
+		identityset.Address(1).String(): {},
+	}
+	require.NoError(blk.VerifyProducerIn(delegates))
+
+	other, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(2))
+	require.NoError(err)
+	require.Equal(ErrUnauthorizedProducer, errors.Cause(other.VerifyProducerIn(delegates)))
+	require.Equal(ErrUnauthorizedProducer, errors.Cause(blk.VerifyProducerIn(nil)))
+
+	genesis := NewBuilder(NewRunnableActionsBuilder().Build()).SetTimestamp(time.Unix(0, 0)).BuildGenesis()
+	require.Equal(ErrMissingProducer, errors.Cause(genesis.VerifyProducerIn(delegates)))
+}
+
+func TestHashProposal(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	h := blk.HashProposal()
+	require.Equal(blk.HashBlock(), h)
+
+	var ens []*endorsement.Endorsement
+	for i := 1; i <= 3; i++ {
+		ens = append(ens, endorsement.NewEndorsement(time.Now(), identityset.PrivateKey(i).PublicKey(), h[:]))
+	}
+	require.NoError(blk.Finalize(ens[:1], time.Now()))
+	require.Equal(h, blk.HashProposal())
+	blk.endorsements = ens
+	require.Equal(h, blk.HashProposal())
+	raw, err := blk.Serialize()
+	require.NoError(err)
+	var newblk Block
+	require.NoError(newblk.Deserialize(raw))
+	require.Len(newblk.Endorsements(), 3)
+	require.Equal(h, newblk.HashProposal())
+
+	// the proposal hash covers the body
+	newblk.Actions = newblk.Actions[:2]
+	require.Equal(ErrTxRootMismatch, errors.Cause(newblk.VerifyTxRoot()))
+}
+
+func TestLink(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	link := blk.Link()
+	require.Equal(uint64(1), link.Height)
+	require.Equal(blk.HashBlock(), link.Hash)
+	require.Equal(blk.HashHeader(), link.Hash)
+	require.Equal(blk.PrevHash(), link.PrevHash)
+	require.NotEqual(link.Hash, link.PrevHash)
+	require.Equal(link, blk.Link())
+}
+
+func TestSummary(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.NoError(blk.SetReceipts(makeReceipts(t, blk, 1)))
+	summary := blk.Summary()
+	require.Equal(blk.Height(), summary.Height)
+	require.Equal(blk.HashBlock(), summary.Hash)
+	require.Equal(blk.Timestamp(), summary.Timestamp)
+	require.Equal(identityset.Address(0).String(), summary.Producer)
+	require.Equal(5, summary.NumActions)
+	require.Equal(blk.TotalGasConsumed(), summary.GasConsumed)
+	require.Equal(uint64(5*10000+10), summary.GasConsumed)
+	amount, err := blk.TotalTransferAmount()
+	require.NoError(err)
+	require.Zero(amount.Cmp(summary.TransferAmount))
+	require.Zero(blk.CalculateTransferAmount().Cmp(summary.TransferAmount))
+
+	summary = (&Block{}).Summary()
+	require.Empty(summary.Producer)
+	require.Zero(summary.NumActions)
+	require.Zero(summary.TransferAmount.Sign())
+}
+
+func TestCompareBlocks(t *testing.T) {
+	require := require.New(t)
+
+	var blks []*Block
+	for i := 0; i < 6; i++ {
+		blk, err := NewTestingBuilder().
+			SetHeight(uint64(i%2 + 1)).
+			SetPrevBlockHash(hash.Hash256b([]byte{byte(i)})).
+			SetTimeStamp(testutil.TimestampNow()).
+			SignAndBuild(identityset.PrivateKey(i))
+		require.NoError(err)
+		blks = append(blks, &blk)
+	}
+	blks = append(blks, nil)
+
+	for _, a := range blks {
+		require.Zero(CompareBlocks(a, a))
+		for _, b := range blks {
+			// antisymmetric
+			require.Equal(-CompareBlocks(a, b), CompareBlocks(b, a))
+			if a != b {
+				require.NotZero(CompareBlocks(a, b))
+			}
+			for _, c := range blks {
+				// transitive
+				if CompareBlocks(a, b) < 0 && CompareBlocks(b, c) < 0 {
+					require.Negative(CompareBlocks(a, c))
+				}
+			}
+		}
+	}
+
+	sorted := append([]*Block{}, blks...)
+	sort.Slice(sorted, func(i, j int) bool { return CompareBlocks(sorted[i], sorted[j]) < 0 })
+	require.Nil(sorted[len(sorted)-1])
+	for i := 1; i < len(sorted)-1; i++ {
+		require.LessOrEqual(sorted[i-1].Height(), sorted[i].Height())
+		if sorted[i-1].Height() == sorted[i].Height() {
+			h1, h2 := sorted[i-1].HashBlock(), sorted[i].HashBlock()
+			require.Negative(bytes.Compare(h1[:], h2[:]))
+		}
+	}
+
+	// a copy of a block is equal to it
+	clone := blks[0].Clone()
+	require.Zero(CompareBlocks(blks[0], clone))
+}
+
+func TestAddEndorsement(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	vote := commitVote(blk.HashProposal())
+	en1, err := endorsement.Endorse(identityset.PrivateKey(1), vote, time.Now())
+	require.NoError(err)
+	require.NoError(blk.AddEndorsement(en1))
+	en2, err := endorsement.Endorse(identityset.PrivateKey(2), vote, time.Now())
+	require.NoError(err)
+	require.NoError(blk.AddEndorsement(en2))
+	require.Equal(blk.HashBlock(), blk.HashProposal())
+
+	// an endorser is only added once
+	en3, err := endorsement.Endorse(identityset.PrivateKey(1), vote, time.Now().Add(time.Second))
+	require.NoError(err)
+	require.NoError(blk.AddEndorsement(en3))
+	require.Equal([]*endorsement.Endorsement{en1, en2}, blk.endorsements)
+
+	// endorsements of another hash or topic, or with a bad signature
+	other, err := endorsement.Endorse(identityset.PrivateKey(3), commitVote(blk.PrevHash()), time.Now())
+	require.NoError(err)
+	for _, en := range []*endorsement.Endorsement{
+		other,
+		endorsement.NewEndorsement(en1.Timestamp(), identityset.PrivateKey(3).PublicKey(), en1.Signature()),
+		endorsement.NewEndorsement(en1.Timestamp().Add(time.Second), en1.Endorser(), en1.Signature()),
+		nil,
+	} {
+		require.Equal(ErrInvalidEndorsement, errors.Cause(blk.AddEndorsement(en)))
+	}
+	require.Len(blk.Endorsements(), 2)
+	for _, en := range blk.Endorsements() {
+		require.True(endorsement.VerifyEndorsement(vote, en))
+	}
+}
+
+func TestVerifyEndorsementQuorum(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 1)
+	delegates := make([]string, 6)
+	for i := range delegates {
+		delegates[i] = identityset.Address(i).String()
+	}
+	vote := commitVote(blk.HashProposal())
+	endorse := func(i int) {
+		en, err := endorsement.Endorse(identityset.PrivateKey(i), vote, time.Now())
+		require.NoError(err)
+		require.NoError(blk.AddEndorsement(en))
+	}
+	require.Equal(ErrInsufficientEndorsements, errors.Cause(blk.VerifyEndorsementQuorum(delegates, 2.0/3)))
+	for i := 0; i < 4; i++ {
+		endorse(i)
+	}
+	// 4 of 6 is not more than 2/3
+	require.Equal(ErrInsufficientEndorsements, errors.Cause(blk.VerifyEndorsementQuorum(delegates, 2.0/3)))
+	require.NoError(blk.VerifyEndorsementQuorum(delegates, 0.5))
+
+	// an endorsement from outside the delegates is not counted
+	endorse(10)
+	require.Equal(ErrInsufficientEndorsements, errors.Cause(blk.VerifyEndorsementQuorum(delegates, 2.0/3)))
+	// nor an endorsement of another block
+	other, err := endorsement.Endorse(identityset.PrivateKey(4), commitVote(blk.PrevHash()), time.Now())
+	require.NoError(err)
+	blk.endorsements = append(blk.endorsements, other)
+	require.Equal(ErrInsufficientEndorsements, errors.Cause(blk.VerifyEndorsementQuorum(delegates, 2.0/3)))
+
+	endorse(5)
+	require.NoError(blk.VerifyEndorsementQuorum(delegates, 2.0/3))
+	// a delegate listed twice is counted once
+	require.NoError(blk.VerifyEndorsementQuorum(append(delegates, delegates[0]), 2.0/3))
+
+	require.Equal(ErrInsufficientEndorsements, errors.Cause(blk.VerifyEndorsementQuorum(nil, 2.0/3)))
+	require.Error(blk.VerifyEndorsementQuorum(delegates, 1))
+	require.Error(blk.VerifyEndorsementQuorum(delegates, -0.1))
+}
+
+func TestSeal(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	receipts := makeReceipts(t, blk, 1)
+	require.NoError(blk.SetReceipts(receipts))
+	vote := commitVote(blk.HashProposal())
+	en1, err := endorsement.Endorse(identityset.PrivateKey(1), vote, time.Now())
+	require.NoError(err)
+	require.NoError(blk.AddEndorsement(en1))
+	require.False(blk.Sealed())
+	raw, err := blk.Serialize()
+	require.NoError(err)
+
+	blk.Seal()
+	require.True(blk.Sealed())
+	require.Equal(ErrBlockSealed, blk.SetReceipts(nil))
+	en2, err := endorsement.Endorse(identityset.PrivateKey(2), vote, time.Now())
+	require.NoError(err)
+	require.Equal(ErrBlockSealed, blk.AddEndorsement(en2))
+	require.Equal(ErrBlockSealed, blk.Finalize([]*endorsement.Endorsement{en2}, time.Now()))
+	require.Equal(receipts, blk.Receipts)
+	require.Equal([]*endorsement.Endorsement{en1}, blk.Endorsements())
+
+	// reads and serialization are allowed
+	r, ok := blk.ReceiptForAction(receipts[0].ActionHash)
+	require.True(ok)
+	require.Equal(receipts[0], r)
+	require.NoError(blk.VerifyTxRoot())
+	again, err := blk.Serialize()
+	require.NoError(err)
+	require.Equal(raw, again)
+
+	// a clone can be modified
+	clone := blk.Clone()
+	require.False(clone.Sealed())
+	require.NoError(clone.AddEndorsement(en2))
+	require.Len(blk.Endorsements(), 1)
+}
+
+func TestConvertFromBlockPbForwardCompat(t *testing.T) {
+	require := require.New(t)
+
+	build := func(v uint32) *Block {
+		blk, err := NewTestingBuilder().
+			SetVersion(v).
+			SetHeight(1).
+			SetTimeStamp(testutil.TimestampNow()).
+			AddActions(makeBlock(t, 2).Actions...).
+			SignAndBuild(identityset.PrivateKey(0))
+		require.NoError(err)
+		return &blk
+	}
+
+	// one version ahead is accepted
+	next := build(version.ProtocolVersion + 1)
+	var blk Block
+	require.NoError(blk.ConvertFromBlockPb(next.ConvertToBlockPb()))
+	require.Equal(uint32(version.ProtocolVersion+1), blk.Version())
+	require.Equal(next.HashBlock(), blk.HashBlock())
+	raw, err := next.Serialize()
+	require.NoError(err)
+	require.NoError(blk.Deserialize(raw))
+
+	// far ahead is rejected
+	far := build(version.ProtocolVersion + 10)
+	err = blk.ConvertFromBlockPb(far.ConvertToBlockPb())
+	require.Equal(ErrUnsupportedFormatVersion, errors.Cause(err))
+
+	// the window is configurable
+	defer func(window uint32) { ForwardCompatVersions = window }(ForwardCompatVersions)
+	ForwardCompatVersions = 10
+	require.NoError(blk.ConvertFromBlockPb(far.ConvertToBlockPb()))
+	ForwardCompatVersions = 0
+	err = blk.ConvertFromBlockPb(next.ConvertToBlockPb())
+	require.Equal(ErrUnsupportedFormatVersion, errors.Cause(err))
+	require.NoError(blk.ConvertFromBlockPb(build(version.ProtocolVersion).ConvertToBlockPb()))
+}
+
+func TestVerifyMinVersion(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 1)
+	require.Equal(uint32(version.ProtocolVersion), blk.Version())
+	require.NoError(blk.VerifyMinVersion(version.ProtocolVersion))
+	require.NoError(blk.VerifyMinVersion(version.ProtocolVersion - 1))
+	err := blk.VerifyMinVersion(version.ProtocolVersion + 1)
+	require.Equal(ErrVersionTooOld, errors.Cause(err))
+	require.Contains(err.Error(), "version 1, minimum 2")
+}
+
+func TestConvertFromBlockPbLenient(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 4)
+	pb := blk.ConvertToBlockPb()
+	badGasPrice := proto.Clone(pb.Body.Actions[0]).(*iotextypes.Action)
+	badGasPrice.Core.GasPrice = "not a number"
+	noAction := proto.Clone(pb.Body.Actions[0]).(*iotextypes.Action)
+	noAction.Core.Action = nil
+	acts := pb.Body.Actions
+	pb.Body.Actions = []*iotextypes.Action{acts[0], badGasPrice, acts[1], acts[2], noAction, acts[3], nil}
+
+	var strict Block
+	require.Error(strict.ConvertFromBlockPb(pb))
+
+	var lenient Block
+	skipped, err := lenient.ConvertFromBlockPbLenient(pb)
+	require.NoError(err)
+	require.Equal([]int{1, 4, 6}, skipped)
+	require.Equal(blk.ActionHashs(), lenient.ActionHashs())
+	require.Equal(blk.HashBlock(), lenient.HashBlock())
+
+	// the tx root does not match once a valid action is skipped
+	pb.Body.Actions = []*iotextypes.Action{acts[0], badGasPrice, acts[2]}
+	skipped, err = lenient.ConvertFromBlockPbLenient(pb)
+	require.NoError(err)
+	require.Equal([]int{1}, skipped)
+	require.Len(lenient.Actions, 2)
+	require.Equal(ErrTxRootMismatch, errors.Cause(lenient.VerifyTxRoot()))
+
+	// a malformed header still fails
+	pb.Header.Core.Timestamp = nil
+	_, err = lenient.ConvertFromBlockPbLenient(pb)
+	require.Error(err)
+}
+
+func TestSerializeForNetwork(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 10)
+	raw, err := blk.SerializeForNetwork()
+	require.NoError(err)
+	// receipts are not part of the block protobuf, so the network form is the same with or without them
+	blk.Receipts = makeReceipts(t, blk, 3)
+	withReceipts, err := blk.SerializeForNetwork()
+	require.NoError(err)
+	require.Equal(raw, withReceipts)
+	pb, err := proto.Marshal(blk.ConvertToBlockPb())
+	require.NoError(err)
+	require.Equal(pb, raw)
+
+	var newblk Block
+	require.NoError(newblk.DeserializeFromNetwork(raw))
+	require.Nil(newblk.Receipts)
+	require.Equal(blk.HashBlock(), newblk.HashBlock())
+	require.Equal(blk.ReceiptRoot(), newblk.ReceiptRoot())
+}
+
+func TestBlockFormatVersion(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	raw, err := blk.Serialize()
+	require.NoError(err)
+	require.Equal(CurrentFormatVersion, raw[0])
+	legacy, err := blk.SerializeForNetwork()
+	require.NoError(err)
+	require.Equal(legacy, raw[1:])
+
+	for _, buf := range [][]byte{raw, legacy} {
+		var newblk Block
+		require.NoError(newblk.Deserialize(buf))
+		require.Equal(blk.HashBlock(), newblk.HashBlock())
+	}
+
+	var newblk Block
+	for _, v := range []byte{0, 2, maxFormatVersion} {
+		err := newblk.Deserialize(append([]byte{v}, legacy...))
+		require.Equal(ErrUnsupportedFormatVersion, errors.Cause(err))
+		require.Contains(err.Error(), fmt.Sprintf("format version %d", v))
+	}
+}
+
+func TestTotalGasConsumed(t *testing.T) {
+	require := require.New(t)
+
+	blk := Block{}
+	require.Zero(blk.TotalGasConsumed())
+	require.Zero(blk.TotalGasUsedByStatus(action.ReceiptStatusSuccess))
+
+	blk.Receipts = []*action.Receipt{
+		{Status: action.ReceiptStatusSuccess, GasConsumed: 10000},
+		{Status: action.ReceiptStatusFailure, GasConsumed: 21000},
+		{Status: action.ReceiptStatusErrExecutionReverted, GasConsumed: 35000},
+		{Status: action.ReceiptStatusSuccess, GasConsumed: 7},
+	}
+	require.EqualValues(66007, blk.TotalGasConsumed())
+	require.EqualValues(10007, blk.TotalGasUsedByStatus(action.ReceiptStatusSuccess))
+	require.EqualValues(21000, blk.TotalGasUsedByStatus(action.ReceiptStatusFailure))
+	require.EqualValues(35000, blk.TotalGasUsedByStatus(action.ReceiptStatusErrExecutionReverted))
+	require.Zero(blk.TotalGasUsedByStatus(action.ReceiptStatusErrOutOfGas))
+
+	// saturate on overflow
+	blk.Receipts = append(blk.Receipts, &action.Receipt{Status: action.ReceiptStatusSuccess, GasConsumed: math.MaxUint64 - 1})
+	require.EqualValues(uint64(math.MaxUint64), blk.TotalGasConsumed())
+	require.EqualValues(uint64(math.MaxUint64), blk.TotalGasUsedByStatus(action.ReceiptStatusSuccess))
+}
+
+func TestVerifyTimestampAfter(t *testing.T) {
+	require := require.New(t)
+
+	now := testutil.TimestampNow()
+	parent, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(now.Add(-10 * time.Second)).
+		SignAndBuild(identityset.PrivateKey(27))
+	require.NoError(err)
+
+	for _, c := range []struct {
+		ts  time.Time
+		err error
+	}{
+		{now.Add(-5 * time.Second), nil},
+		{now.Add(time.Second), nil},
+		{now.Add(-10 * time.Second), ErrTimestampBeforeParent},
+		{now.Add(-20 * time.Second), ErrTimestampBeforeParent},
+		{now.Add(time.Minute), ErrTimestampInFuture},
+	} {
+		blk, err := NewTestingBuilder().
+			SetHeight(2).
+			SetTimeStamp(c.ts).
+			SetPrevBlockHash(parent.HashBlock()).
+			SignAndBuild(identityset.PrivateKey(27))
+		require.NoError(err)
+		require.Equal(c.err, errors.Cause(blk.VerifyTimestampAfter(&parent.Header, 10*time.Second)))
+	}
+}
+
+func TestDeserializeWithClock(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1600000000, 0)
+	clock := func() time.Time { return now }
+	for _, c := range []struct {
+		ts  time.Time
+		err error
+	}{
+		{now.Add(-time.Hour), nil},
+		{now, nil},
+		{now.Add(10 * time.Second), nil},
+		{now.Add(10*time.Second + time.Nanosecond), ErrTimestampInFuture},
+		{now.Add(time.Hour), ErrTimestampInFuture},
+	} {
+		blk, err := NewTestingBuilder().
+			SetHeight(2).
+			SetTimeStamp(c.ts).
+			SignAndBuild(identityset.PrivateKey(27))
+		require.NoError(err)
+		raw, err := blk.Serialize()
+		require.NoError(err)
+
+		newblk, err := DeserializeWithClock(raw, clock, 10*time.Second)
+		require.Equal(c.err, errors.Cause(err))
+		if c.err == nil {
+			require.Equal(blk.HashBlock(), newblk.HashBlock())
+		} else {
+			require.Nil(newblk)
+		}
+		// plain Deserialize does not check the timestamp
+		require.NoError((&Block{}).Deserialize(raw))
+	}
+
+	_, err := DeserializeWithClock([]byte{2}, clock, 0)
+	require.Equal(ErrUnsupportedFormatVersion, errors.Cause(err))
+}
+
+func TestBlockEqual(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	raw, err := blk.Serialize()
+	require.NoError(err)
+	var newblk Block
+	require.NoError(newblk.Deserialize(raw))
+	// memoized hash on one side only does not matter
+	blk.HashBlock()
+	require.True(blk.Equal(&newblk))
+	require.True(newblk.Header.Equal(&blk.Header))
+
+	// nil and empty receipts are different
+	blk.Receipts = []*action.Receipt{}
+	require.False(blk.Equal(&newblk))
+	newblk.Receipts = []*action.Receipt{}
+	require.True(blk.Equal(&newblk))
+	blk.Receipts = makeReceipts(t, blk, 1)
+	newblk.Receipts = makeReceipts(t, &newblk, 1)
+	require.True(blk.Equal(&newblk))
+	newblk.Receipts[0].GasConsumed++
+	require.False(blk.Equal(&newblk))
+	newblk.Receipts = blk.Receipts
+
+	// action order matters
+	newblk.Actions[0], newblk.Actions[1] = newblk.Actions[1], newblk.Actions[0]
+	require.False(blk.Equal(&newblk))
+	newblk.Actions[0], newblk.Actions[1] = newblk.Actions[1], newblk.Actions[0]
+	require.True(blk.Equal(&newblk))
+
+	newblk.Header.height++
+	require.False(blk.Equal(&newblk))
+	require.False(blk.Header.Equal(&newblk.Header))
+	require.False(blk.Equal(nil))
+}
+
+func TestBlockCounts(t *testing.T) {
+	require := require.New(t)
+
+	blk := Block{}
+	require.Zero(blk.NumActions())
+	require.Zero(blk.NumReceipts())
+	require.Zero(blk.NumLogs())
+
+	blk = *makeBlock(t, 4)
+	require.Equal(4, blk.NumActions())
+	require.Zero(blk.NumReceipts())
+	require.Zero(blk.NumLogs())
+	blk.Receipts = makeReceipts(t, &blk, 3)
+	require.Equal(4, blk.NumReceipts())
+	require.Equal(12, blk.NumLogs())
+}
+
+func TestVerifyGasLimit(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError((&Block{}).VerifyGasLimit(0))
+
+	var acts []action.SealedEnvelope
+	for i := 0; i < 3; i++ {
+		selp, err := action.SignedTransfer(identityset.Address(9).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(10))
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	blk := &Block{Body: Body{Actions: acts}}
+	require.NoError(blk.VerifyGasLimit(300000))
+	err := blk.VerifyGasLimit(299999)
+	require.Equal(ErrBlockGasExceeded, errors.Cause(err))
+	require.Contains(err.Error(), "declared gas 300000")
+
+	// the receipts consume 10000+i gas for the i-th action
+	require.NoError(blk.SetReceipts(makeReceipts(t, blk, 0)))
+	require.NoError(blk.VerifyGasLimit(299999))
+	require.NoError(blk.VerifyGasLimit(30003))
+	err = blk.VerifyGasLimit(30002)
+	require.Equal(ErrBlockGasExceeded, errors.Cause(err))
+	require.Contains(err.Error(), "consumed gas 30003")
+}
+
+func TestVerifyMinGasPrice(t *testing.T) {
+	require := require.New(t)
+
+	var acts []action.SealedEnvelope
+	for i, price := range []int64{10, 20, 5, 10} {
+		selp, err := action.SignedTransfer(identityset.Address(9).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(price))
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	blk := &Block{Body: Body{Actions: acts}}
+	require.NoError(blk.VerifyMinGasPrice(big.NewInt(5)))
+	require.NoError(blk.VerifyMinGasPrice(nil))
+	err := blk.VerifyMinGasPrice(big.NewInt(10))
+	require.Equal(ErrGasPriceTooLow, errors.Cause(err))
+	require.Contains(err.Error(), "action 2 has gas price 5, floor 10")
+
+	// an action without gas price is only accepted without a floor
+	tsf, err := action.NewTransfer(1, big.NewInt(1), identityset.Address(9).String(), nil, 100000, nil)
+	require.NoError(err)
+	selp, err := action.Sign((&action.EnvelopeBuilder{}).SetNonce(1).SetGasLimit(100000).SetAction(tsf).Build(), identityset.PrivateKey(2))
+	require.NoError(err)
+	blk = &Block{Body: Body{Actions: []action.SealedEnvelope{selp}}}
+	require.NoError(blk.VerifyMinGasPrice(big.NewInt(0)))
+	err = blk.VerifyMinGasPrice(big.NewInt(1))
+	require.Equal(ErrGasPriceTooLow, errors.Cause(err))
+	require.Contains(err.Error(), "action 0 has gas price 0")
+}
+
+func TestDataGas(t *testing.T) {
+	require := require.New(t)
+
+	gas, err := (&Block{}).DataGas()
+	require.NoError(err)
+	require.Zero(gas)
+
+	producer := identityset.PrivateKey(27)
+	tsf, err := action.SignedTransfer(identityset.Address(28).String(), producer, 1, big.NewInt(1), []byte{1, 0, 2}, 100000, big.NewInt(0))
+	require.NoError(err)
+	exec, err := action.SignedExecution(identityset.Address(29).String(), producer, 2, big.NewInt(0), 100000, big.NewInt(0), make([]byte, 10))
+	require.NoError(err)
+	noPayload, err := action.SignedTransfer(identityset.Address(28).String(), producer, 3, big.NewInt(1), nil, 100000, big.NewInt(0))
+	require.NoError(err)
+	blk := &Block{Body: Body{Actions: []action.SealedEnvelope{noPayload}}}
+	gas, err = blk.DataGas()
+	require.NoError(err)
+	require.Zero(gas)
+
+	// 11 zero bytes and 2 non-zero bytes
+	blk.Actions = append(blk.Actions, tsf, exec)
+	gas, err = blk.DataGas()
+	require.NoError(err)
+	require.EqualValues(13*action.ExecutionDataGas, gas)
+
+	defer func(zero, nonZero uint64) {
+		DataGasPerZeroByte, DataGasPerNonZeroByte = zero, nonZero
+	}(DataGasPerZeroByte, DataGasPerNonZeroByte)
+	DataGasPerZeroByte, DataGasPerNonZeroByte = 4, 16
+	gas, err = blk.DataGas()
+	require.NoError(err)
+	require.EqualValues(11*4+2*16, gas)
+	DataGasPerNonZeroByte = math.MaxUint64
+	_, err = blk.DataGas()
+	require.Error(err)
+}
+
+func TestCumulativeGasUsed(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 4)
+	_, err := blk.CumulativeGasUsed()
+	require.Equal(ErrReceiptMismatch, errors.Cause(err))
+
+	receipts := makeReceipts(t, blk, 0)
+	for i, gas := range []uint64{21000, 0, 100000, 5} {
+		receipts[i].GasConsumed = gas
+	}
+	require.NoError(blk.SetReceipts(receipts))
+	cumulative, err := blk.CumulativeGasUsed()
+	require.NoError(err)
+	require.Len(cumulative, 4)
+	for i, expected := range []uint64{21000, 21000, 121000, 121005} {
+		h, err := blk.Actions[i].Hash()
+		require.NoError(err)
+		require.Equal(hex.EncodeToString(h[:]), blk.ActionHashs()[i])
+		require.Equal(expected, cumulative[h])
+	}
+	last, err := blk.Actions[3].Hash()
+	require.NoError(err)
+	require.Equal(blk.TotalGasConsumed(), cumulative[last])
+
+	// saturates on overflow
+	for i, gas := range []uint64{math.MaxUint64 - 10, 5, 10, 1} {
+		receipts[i].GasConsumed = gas
+	}
+	require.NoError(blk.SetReceipts(receipts))
+	cumulative, err = blk.CumulativeGasUsed()
+	require.NoError(err)
+	for i, expected := range []uint64{math.MaxUint64 - 10, math.MaxUint64 - 5, math.MaxUint64, math.MaxUint64} {
+		h, err := blk.Actions[i].Hash()
+		require.NoError(err)
+		require.Equal(expected, cumulative[h])
+	}
+	require.Equal(blk.TotalGasConsumed(), cumulative[last])
+
+	blk.Actions = append(blk.Actions, blk.Actions[0])
+	_, err = blk.CumulativeGasUsed()
+	require.Equal(ErrDuplicateAction, errors.Cause(err))
+}
+
+func TestTouchedAddresses(t *testing.T) {
+	require := require.New(t)
+
+	blk := Block{}
+	addrs, err := blk.TouchedAddresses()
+	require.NoError(err)
+	require.Empty(addrs)
+
+	blk = *makeBlock(t, 3)
+	expected := make(map[string]bool)
+	for _, selp := range blk.Actions {
+		expected[selp.SrcPubkey().Address().String()] = true
+		dst, ok := selp.Destination()
+		require.True(ok)
+		expected[dst] = true
+	}
+	addrs, err = blk.TouchedAddresses()
+	require.NoError(err)
+	require.Equal(len(expected), len(addrs))
+
+	// log emitters are added, malformed log addresses are skipped
+	blk.Receipts = makeReceipts(t, &blk, 2)
+	blk.Receipts[0].AddLogs(&action.Log{Address: "1"})
+	expected[identityset.Address(0).String()] = true
+	expected[identityset.Address(1).String()] = true
+	addrs, err = blk.TouchedAddresses()
+	require.NoError(err)
+	require.Equal(len(expected), len(addrs))
+	for i, addr := range addrs {
+		require.True(expected[addr.String()])
+		if i > 0 {
+			require.True(addrs[i-1].String() < addr.String())
+		}
+	}
+
+	// malformed recipient is an error
+	tsf, err := action.NewTransfer(1, big.NewInt(1), "io1invalid", nil, 100000, big.NewInt(1))
+	require.NoError(err)
+	elp := (&action.EnvelopeBuilder{}).SetAction(tsf).SetGasLimit(100000).SetGasPrice(big.NewInt(1)).SetNonce(1).Build()
+	blk.Actions = append(blk.Actions, action.FakeSeal(elp, identityset.PrivateKey(0).PublicKey()))
+	_, err = blk.TouchedAddresses()
+	require.Error(err)
+}
+
+func TestBlockClone(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	blk.Receipts = makeReceipts(t, blk, 2)
+	bf, err := bloom.NewBloomFilterLegacy(2048, 3)
+	require.NoError(err)
+	bf.Add([]byte("topic"))
+	blk.logsBloom = bf
+	txIndex, _ := blk.TxLogIndexMap()
+	sig := append([]byte(nil), blk.blockSig...)
+
+	clone := blk.Clone()
+	require.True(blk.Equal(clone))
+	require.Equal(blk.HashBlock(), clone.HashBlock())
+	require.Equal(bf.Bytes(), clone.LogsBloomfilter().Bytes())
+
+	// mutate the clone
+	clone.Receipts[0].Status = action.ReceiptStatusFailure
+	clone.Receipts[1].Logs()[0].Data[0]++
+	clone.Receipts[2].AddLogs(&action.Log{Address: identityset.Address(3).String()})
+	clone.SetReceipts(clone.Receipts[:1])
+	clone.Actions[0] = clone.Actions[1]
+	clone.blockSig[0]++
+	clone.LogsBloomfilter().Add([]byte("another topic"))
+
+	require.Equal(action.ReceiptStatusSuccess, blk.Receipts[0].Status)
+	require.Equal(byte('c'), blk.Receipts[1].Logs()[0].Data[0])
+	require.Len(blk.Receipts[2].Logs(), 2)
+	require.Equal(3, blk.NumReceipts())
+	newTxIndex, _ := blk.TxLogIndexMap()
+	require.Equal(txIndex, newTxIndex)
+	require.NoError(blk.VerifyTxRoot())
+	require.Equal(sig, blk.blockSig)
+	require.False(blk.LogsBloomfilter().Exist([]byte("another topic")))
+}
+
+func TestLogIndexEncoding(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	data, err := blk.MarshalLogIndex()
+	require.NoError(err)
+	txIndex, logIndex, err := UnmarshalLogIndex(data)
+	require.NoError(err)
+	require.Empty(txIndex)
+	require.Empty(logIndex)
+
+	blk.SetReceipts(makeReceipts(t, blk, 300))
+	data, err = blk.MarshalLogIndex()
+	require.NoError(err)
+	txIndex, logIndex, err = UnmarshalLogIndex(data)
+	require.NoError(err)
+	expectedTx, expectedLog := blk.TxLogIndexMap()
+	require.Equal(expectedTx, txIndex)
+	require.Equal(expectedLog, logIndex)
+
+	for _, bad := range [][]byte{
+		nil,
+		{2, 0},
+		{logIndexVersion1},
+		{logIndexVersion1, 1},
+		data[:len(data)-1],
+		data[:len(data)-34],
+		append(data, 0),
+	} {
+		_, _, err = UnmarshalLogIndex(bad)
+		require.Equal(ErrInvalidLogIndex, errors.Cause(err))
+	}
+}
+
+func TestVerifyReceiptsMatchActions(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.Equal(ErrReceiptMismatch, errors.Cause(blk.VerifyReceiptsMatchActions()))
+	receipts := makeReceipts(t, blk, 1)
+	blk.SetReceipts(receipts)
+	require.NoError(blk.VerifyReceiptsMatchActions())
+
+	for _, v := range []struct {
+		name     string
+		receipts []*action.Receipt
+		msg      string
+	}{
+		{"duplicate", append(receipts[:4:4], receipts[3]), "receipt 4 of action"},
+		{"missing", receipts[:4], "action 4"},
+		{"orphaned", append(receipts[:4:4], &action.Receipt{ActionHash: hash.Hash256b([]byte("orphan"))}), "receipt 4 of action"},
+		{"nil", append(receipts[:4:4], nil), "receipt 4 is nil"},
+	} {
+		blk.SetReceipts(v.receipts)
+		err := blk.VerifyReceiptsMatchActions()
+		require.Equal(ErrReceiptMismatch, errors.Cause(err), v.name)
+		require.Contains(err.Error(), v.msg, v.name)
+	}
+
+	// an action included twice needs two receipts
+	blk.Actions = append(blk.Actions, blk.Actions[0])
+	blk.SetReceipts(receipts)
+	require.Equal(ErrReceiptMismatch, errors.Cause(blk.VerifyReceiptsMatchActions()))
+	blk.SetReceipts(append(receipts[:5:5], receipts[0]))
+	require.NoError(blk.VerifyReceiptsMatchActions())
+}
+
+func TestSetReceipts(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	blk.SetReceipts(makeReceipts(t, blk, 2))
+	h0, err := blk.Actions[0].Hash()
+	require.NoError(err)
+	h2, err := blk.Actions[2].Hash()
+	require.NoError(err)
+	txIndex, logIndex := blk.TxLogIndexMap()
+	require.Len(txIndex, 3)
+	require.EqualValues(2, txIndex[h2])
+	require.EqualValues(4, logIndex[h2])
+
+	// replace receipts with a new slice
+	receipts := makeReceipts(t, blk, 1)[:2]
+	blk.SetReceipts(receipts)
+	txIndex, _ = blk.TxLogIndexMap()
+	require.Len(txIndex, 2)
+	_, ok := txIndex[h2]
+	require.False(ok)
+	_, ok = blk.ReceiptForAction(h2)
+	require.False(ok)
+
+	// mutate the receipts in place, which the cache cannot detect on its own
+	receipts[0], receipts[1] = receipts[1], receipts[0]
+	blk.SetReceipts(receipts)
+	txIndex, logIndex = blk.TxLogIndexMap()
+	require.EqualValues(1, txIndex[h0])
+	require.EqualValues(1, logIndex[h0])
+	r, ok := blk.ReceiptForAction(h0)
+	require.True(ok)
+	require.Equal(receipts[1], r)
+
+	blk.SetReceipts(nil)
+	txIndex, logIndex = blk.TxLogIndexMap()
+	require.Empty(txIndex)
+	require.Empty(logIndex)
+	require.Zero(blk.NumReceipts())
+}
+
+func TestActionCountBySender(t *testing.T) {
+	require := require.New(t)
+
+	counts, err := (&Block{}).ActionCountBySender()
+	require.NoError(err)
+	require.NotNil(counts)
+	require.Empty(counts)
+
+	var acts []action.SealedEnvelope
+	for i, key := range []int{1, 1, 2, 1, 1} {
+		selp, err := action.SignedTransfer(identityset.Address(9).String(), identityset.PrivateKey(key), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(10))
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	blk := &Block{Body: Body{Actions: acts}}
+	counts, err = blk.ActionCountBySender()
+	require.NoError(err)
+	require.Len(counts, 2)
+	for sender, n := range counts {
+		switch sender.String() {
+		case identityset.Address(1).String():
+			require.Equal(4, n)
+		case identityset.Address(2).String():
+			require.Equal(1, n)
+		default:
+			require.Fail("unexpected sender", sender.String())
+		}
+	}
+
+	// the senders are cached, and the cache follows Actions
+	idx, err := blk.indexSenders()
+	require.NoError(err)
+	again, err := blk.indexSenders()
+	require.NoError(err)
+	require.True(idx == again)
+	blk.Actions = acts[:2]
+	counts, err = blk.ActionCountBySender()
+	require.NoError(err)
+	require.Len(counts, 1)
+
+	blk.Actions = append(blk.Actions, action.SealedEnvelope{})
+	_, err = blk.ActionCountBySender()
+	require.Error(err)
+}
+
+func TestVerifyNonceContinuity(t *testing.T) {
+	require := require.New(t)
+
+	build := func(nonces map[int][]uint64) *Block {
+		var acts []action.SealedEnvelope
+		for sender := 1; sender <= 3; sender++ {
+			for _, n := range nonces[sender] {
+				tsf, err := action.SignedTransfer(identityset.Address(10).String(), identityset.PrivateKey(sender), n, big.NewInt(1), nil, 100000, big.NewInt(10))
+				require.NoError(err)
+				acts = append(acts, tsf)
+			}
+		}
+		return &Block{Body: Body{Actions: acts}}
+	}
+	accountNonces := map[address.Address]uint64{
+		identityset.Address(1): 3,
+		identityset.Address(2): 9,
+		identityset.Address(3): 1,
+	}
+
+	// actions need not be sorted by nonce
+	blk := build(map[int][]uint64{1: {4, 3, 5}, 2: {9}})
+	require.NoError(blk.VerifyNonceContinuity(accountNonces))
+	require.NoError((&Block{}).VerifyNonceContinuity(nil))
+
+	// a leading gap
+	blk = build(map[int][]uint64{1: {3, 4}, 2: {10, 11}})
+	err := blk.VerifyNonceContinuity(accountNonces)
+	require.Equal(ErrNonceGap, errors.Cause(err))
+	require.Contains(err.Error(), identityset.Address(2).String())
+	require.Contains(err.Error(), "nonce 10, expecting 9")
+
+	// a gap or a duplicate in the block
+	for _, nonces := range [][]uint64{{1, 3}, {1, 2, 2}} {
+		blk = build(map[int][]uint64{3: nonces})
+		err = blk.VerifyNonceContinuity(accountNonces)
+		require.Equal(ErrNonceGap, errors.Cause(err))
+		require.Contains(err.Error(), identityset.Address(3).String())
+	}
+
+	// a sender without account nonce
+	blk = build(map[int][]uint64{1: {3}})
+	require.Error(blk.VerifyNonceContinuity(map[address.Address]uint64{identityset.Address(2): 9}))
+}
+
+func TestSenderNonceRanges(t *testing.T) {
+	require := require.New(t)
+
+	var acts []action.SealedEnvelope
+	for _, v := range []struct {
+		sender int
+		nonce  uint64
+	}{
+		{1, 5}, {2, 9}, {1, 3}, {1, 7}, {2, 10}, {3, 1},
+	} {
+		tsf, err := action.SignedTransfer(identityset.Address(10).String(), identityset.PrivateKey(v.sender), v.nonce, big.NewInt(1), nil, 100000, big.NewInt(10))
+		require.NoError(err)
+		acts = append(acts, tsf)
+	}
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(acts...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+
+	ranges, err := blk.SenderNonceRanges()
+	require.NoError(err)
+	require.Len(ranges, 3)
+	expected := map[string][2]uint64{
+		identityset.Address(1).String(): {3, 7},
+		identityset.Address(2).String(): {9, 10},
+		identityset.Address(3).String(): {1, 1},
+	}
+	for addr, r := range ranges {
+		require.Equal(expected[addr.String()], r, addr.String())
+	}
+
+	ranges, err = (&Block{}).SenderNonceRanges()
+	require.NoError(err)
+	require.Empty(ranges)
+}
+
+func TestGasPrices(t *testing.T) {
+	require := require.New(t)
+
+	blk := Block{}
+	prices, err := blk.GasPrices()
+	require.NoError(err)
+	require.Empty(prices)
+
+	blk = *makeBlock(t, 4)
+	// an action without gas price
+	tsf, err := action.NewTransfer(1, big.NewInt(1), identityset.Address(1).String(), nil, 100000, nil)
+	require.NoError(err)
+	elp := (&action.EnvelopeBuilder{}).SetAction(tsf).SetGasLimit(100000).SetNonce(1).Build()
+	selp, err := action.Sign(elp, identityset.PrivateKey(1))
+	require.NoError(err)
+	blk.Actions = append(blk.Actions, selp)
+
+	prices, err = blk.GasPrices()
+	require.NoError(err)
+	require.Equal(blk.NumActions(), len(prices))
+	require.Equal(len(blk.ActionHashs()), len(prices))
+	for i := 0; i < 4; i++ {
+		require.Equal(blk.Actions[i].GasPrice(), prices[i])
+	}
+	require.Zero(prices[4].Sign())
+
+	// the prices are copies
+	prices[0].SetUint64(0)
+	require.NotZero(blk.Actions[0].GasPrice().Sign())
+}
+
+func TestVerifyDeltaStateDigest(t *testing.T) {
+	require := require.New(t)
+
+	digest := hash.Hash256b([]byte("state"))
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(makeBlock(t, 2).Actions...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SetDeltaStateDigest(digest).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.Equal(digest, blk.DeltaStateDigest())
+	require.NoError(blk.VerifyDeltaStateDigest(digest))
+	require.True(blk.Header.VerifyDeltaStateDigest(digest))
+
+	other := hash.Hash256b([]byte("diverged"))
+	err = blk.VerifyDeltaStateDigest(other)
+	require.Equal(ErrDeltaStateMismatch, errors.Cause(err))
+	require.Contains(err.Error(), hex.EncodeToString(digest[:]))
+	require.Contains(err.Error(), hex.EncodeToString(other[:]))
+}
+
+func TestVerifyIntrinsicGas(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError((&Block{}).VerifyIntrinsicGas())
+	blk := makeBlock(t, 3)
+	require.NoError(blk.VerifyIntrinsicGas())
+
+	// an execution with 100 bytes of data needs 10000 + 100 * 100 gas
+	exec, err := action.SignedExecution(identityset.Address(2).String(), identityset.PrivateKey(1), 1, big.NewInt(0), 20000, big.NewInt(10), make([]byte, 100))
+	require.NoError(err)
+	blk.Actions = append(blk.Actions, exec)
+	require.NoError(blk.VerifyIntrinsicGas())
+	exec, err = action.SignedExecution(identityset.Address(2).String(), identityset.PrivateKey(1), 2, big.NewInt(0), 19999, big.NewInt(10), make([]byte, 100))
+	require.NoError(err)
+	blk.Actions = append(blk.Actions, exec, blk.Actions[0])
+	err = blk.VerifyIntrinsicGas()
+	require.Equal(action.ErrIntrinsicGas, errors.Cause(err))
+	require.Contains(err.Error(), "action 4 ")
+}
+
+func TestWeightedMedianGasPrice(t *testing.T) {
+	require := require.New(t)
+
+	median, err := (&Block{}).WeightedMedianGasPrice()
+	require.NoError(err)
+	require.Zero(median.Sign())
+
+	build := func(pairs ...[2]int64) *Block {
+		acts := make([]action.SealedEnvelope, 0, len(pairs))
+		for i, p := range pairs {
+			var price *big.Int
+			if p[0] >= 0 {
+				price = big.NewInt(p[0])
+			}
+			tsf, err := action.NewTransfer(uint64(i+1), big.NewInt(1), identityset.Address(1).String(), nil, uint64(p[1]), price)
+			require.NoError(err)
+			elp := (&action.EnvelopeBuilder{}).SetAction(tsf).SetGasLimit(uint64(p[1])).SetGasPrice(price).SetNonce(uint64(i + 1)).Build()
+			selp, err := action.Sign(elp, identityset.PrivateKey(1))
+			require.NoError(err)
+			acts = append(acts, selp)
+		}
+		return &Block{Body: Body{Actions: acts}}
+	}
+	for _, c := range []struct {
+		pairs  [][2]int64
+		median int64
+	}{
+		// (price, gas limit), a negative price stands for nil
+		{[][2]int64{{10, 100}}, 10},
+		// the plain median is 20, but most of the gas is priced 5
+		{[][2]int64{{20, 10000}, {30, 10000}, {5, 50000}, {10, 10000}}, 5},
+		{[][2]int64{{30, 30000}, {10, 10000}, {20, 20000}}, 20},
+		// exactly half of the gas at or below 10
+		{[][2]int64{{10, 20000}, {40, 20000}}, 10},
+		{[][2]int64{{-1, 60000}, {100, 50000}}, 0},
+		{[][2]int64{{100, 0}, {200, 0}}, 0},
+	} {
+		median, err := build(c.pairs...).WeightedMedianGasPrice()
+		require.NoError(err)
+		require.Zero(big.NewInt(c.median).Cmp(median), c.pairs)
+	}
+}
+
+func TestAge(t *testing.T) {
+	require := require.New(t)
+
+	ts := time.Unix(1600000000, 0)
+	blk, err := NewTestingBuilder().SetHeight(1).SetTimeStamp(ts).SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.Equal(5*time.Second, blk.Age(ts.Add(5*time.Second)))
+	require.Equal(time.Hour, blk.Age(ts.Add(time.Hour)))
+	require.Zero(blk.Age(ts))
+	// a timestamp slightly in the future
+	require.Zero(blk.Age(ts.Add(-time.Millisecond)))
+}
+
+func TestVerifySlot(t *testing.T) {
+	require := require.New(t)
+
+	epochStart := time.Unix(1600000000, 0)
+	interval := 5 * time.Second
+	build := func(ts time.Time) *Block {
+		blk, err := NewTestingBuilder().SetHeight(1).SetTimeStamp(ts).SignAndBuild(identityset.PrivateKey(0))
+		require.NoError(err)
+		return &blk
+	}
+
+	// slot 3 spans [15s, 20s)
+	for _, offset := range []time.Duration{15 * time.Second, 17 * time.Second, 20*time.Second - time.Nanosecond} {
+		require.NoError(build(epochStart.Add(offset)).VerifySlot(epochStart, interval, 3))
+	}
+	// a full slot early
+	err := build(epochStart.Add(12*time.Second)).VerifySlot(epochStart, interval, 3)
+	require.Equal(ErrWrongSlot, errors.Cause(err))
+	require.Contains(err.Error(), "slot 2, expecting slot 3")
+	require.Equal(ErrWrongSlot, errors.Cause(build(epochStart.Add(20*time.Second)).VerifySlot(epochStart, interval, 3)))
+	require.Equal(ErrWrongSlot, errors.Cause(build(epochStart.Add(-time.Second)).VerifySlot(epochStart, interval, 0)))
+	require.NoError(build(epochStart).VerifySlot(epochStart, interval, 0))
+	require.Error(build(epochStart).VerifySlot(epochStart, 0, 0))
+}
+
+func TestVerifyProducerSignature(t *testing.T) {
+	require := require.New(t)
+
+	blk, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(testutil.TimestampNow()).
+		AddActions(makeBlock(t, 2).Actions...).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.NoError(blk.VerifyProducerSignature())
+
+	// flipped signature bytes
+	sig := blk.blockSig
+	blk.blockSig = make([]byte, len(sig))
+	for i := range sig {
+		blk.blockSig[i] = ^sig[i]
+	}
+	require.Equal(ErrInvalidProducerSignature, errors.Cause(blk.VerifyProducerSignature()))
+	blk.blockSig = nil
+	require.Equal(ErrInvalidProducerSignature, errors.Cause(blk.VerifyProducerSignature()))
+
+	// a header field changed after signing
+	blk.blockSig = sig
+	require.NoError(blk.VerifyProducerSignature())
+	blk.height++
+	require.Equal(ErrInvalidProducerSignature, errors.Cause(blk.VerifyProducerSignature()))
+
+	blk.pubkey = nil
+	require.Equal(ErrMissingProducer, errors.Cause(blk.VerifyProducerSignature()))
+}
+
+func TestTPS(t *testing.T) {
+	require := require.New(t)
+
+	ts := time.Unix(1600000000, 0)
+	build := func(ts time.Time, n int) *Block {
+		blk, err := NewTestingBuilder().
+			SetHeight(2).
+			SetTimeStamp(ts).
+			AddActions(makeBlock(t, n).Actions...).
+			SignAndBuild(identityset.PrivateKey(0))
+		require.NoError(err)
+		return &blk
+	}
+	parent := build(ts, 0)
+	tps, err := build(ts.Add(time.Second), 30).TPS(&parent.Header)
+	require.NoError(err)
+	require.Equal(30.0, tps)
+	tps, err = build(ts.Add(500*time.Millisecond), 30).TPS(&parent.Header)
+	require.NoError(err)
+	require.Equal(60.0, tps)
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		_, err = build(ts.Add(d), 30).TPS(&parent.Header)
+		require.Equal(ErrTimestampBeforeParent, errors.Cause(err))
+	}
+}
+
+func TestVerifyParent(t *testing.T) {
+	require := require.New(t)
+
+	parent := makeBlock(t, 2)
+	child := func(height uint64, prevHash hash.Hash256) *Block {
+		blk, err := NewBuilder(NewRunnableActionsBuilder().Build()).
+			SetHeight(height).
+			SetTimestamp(parent.Timestamp().Add(time.Second)).
+			SetPrevBlockHash(prevHash).
+			SignAndBuild(identityset.PrivateKey(1))
+		require.NoError(err)
+		return &blk
+	}
+
+	require.NoError(child(2, parent.HashBlock()).VerifyParent(parent))
+	for _, height := range []uint64{0, 1, 3} {
+		err := child(height, parent.HashBlock()).VerifyParent(parent)
+		require.Equal(ErrParentHeightMismatch, errors.Cause(err))
+	}
+	for _, prevHash := range []hash.Hash256{hash.ZeroHash256, parent.PrevHash(), parent.HashHeaderCore()} {
+		err := child(2, prevHash).VerifyParent(parent)
+		require.Equal(ErrPrevHashMismatch, errors.Cause(err))
+	}
+}
+
+func TestActionTypeMask(t *testing.T) {
+	require := require.New(t)
+
+	require.Zero((&Block{}).ActionTypeMask())
+
+	tsf, err := action.SignedTransfer(identityset.Address(2).String(), identityset.PrivateKey(1), 1, big.NewInt(1), nil, 100000, big.NewInt(10))
+	require.NoError(err)
+	exec, err := action.SignedExecution(identityset.Address(3).String(), identityset.PrivateKey(1), 2, big.NewInt(0), 100000, big.NewInt(10), []byte{1})
+	require.NoError(err)
+	grant := (&action.GrantRewardBuilder{}).SetRewardType(action.BlockReward).SetHeight(1).Build()
+	grantSelp, err := action.Sign((&action.EnvelopeBuilder{}).SetNonce(3).SetAction(&grant).Build(), identityset.PrivateKey(0))
+	require.NoError(err)
+
+	for _, c := range []struct {
+		acts []action.SealedEnvelope
+		mask uint64
+	}{
+		{[]action.SealedEnvelope{tsf, tsf}, ActionTypeTransfer},
+		{[]action.SealedEnvelope{exec}, ActionTypeExecution},
+		{[]action.SealedEnvelope{grantSelp, tsf, exec}, ActionTypeGrantReward | ActionTypeTransfer | ActionTypeExecution},
+		{[]action.SealedEnvelope{tsf, {}}, ActionTypeTransfer | ActionTypeOther},
+	} {
+		blk := &Block{Body: Body{Actions: c.acts}}
+		require.Equal(c.mask, blk.ActionTypeMask())
+	}
+
+	// every known type has its own bit
+	seen := make(map[uint64]bool)
+	for _, bit := range actionTypeBits {
+		require.NotEqual(ActionTypeOther, bit)
+		require.False(seen[bit])
+		seen[bit] = true
+	}
+}
+
+func TestActionsOfType(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		acts  []action.SealedEnvelope
+		kinds []string
+	)
+	for i := 0; i < 6; i++ {
+		var (
+			selp action.SealedEnvelope
+			err  error
+		)
+		if i%3 == 0 {
+			selp, err = action.SignedExecution(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(0), 100000, big.NewInt(10), []byte{byte(i)})
+			kinds = append(kinds, "execution")
+		} else {
+			selp, err = action.SignedTransfer(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(10))
+			kinds = append(kinds, "transfer")
+		}
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(acts...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+
+	for _, kind := range []string{"transfer", "execution"} {
+		var expected []action.SealedEnvelope
+		for i := range acts {
+			if kinds[i] == kind {
+				expected = append(expected, acts[i])
+			}
+		}
+		require.Equal(expected, blk.ActionsOfType(kind))
+	}
+	for _, kind := range []string{"grantReward", "Transfer", "unknown", ""} {
+		found := blk.ActionsOfType(kind)
+		require.NotNil(found)
+		require.Empty(found)
+	}
+
+	// an action without an envelope is skipped
+	numTsfs := len(blk.ActionsOfType("transfer"))
+	blk.Actions = append(blk.Actions, action.SealedEnvelope{})
+	require.Len(blk.ActionsOfType("transfer"), numTsfs)
+	require.Empty(blk.ActionsOfType(""))
+}
 
-	hashes := block.ActionHashs()
-	for i := range hashes {
-		h, err := actions[i].Hash()
+func TestTotalTransferAmount(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		acts     []action.SealedEnvelope
+		expected = big.NewInt(0)
+	)
+	for i, iotx := range []int64{1, 10000000000, 9000000000000, 0} {
+		amount := unit.ConvertIotxToRau(iotx)
+		tsf, err := action.SignedTransfer(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), amount, nil, 100000, big.NewInt(10))
 		require.NoError(err)
-		require.Equal(hex.EncodeToString(h[:]), hashes[i])
+		acts = append(acts, tsf)
+		expected.Add(expected, amount)
 	}
+	value := unit.ConvertIotxToRau(5000000000)
+	exec, err := action.SignedExecution(identityset.Address(9).String(), identityset.PrivateKey(1), 5, value, 100000, big.NewInt(10), []byte{1})
+	require.NoError(err)
+	expected.Add(expected, value)
+	// staking actions carry an amount but do not move value
+	deposit, err := action.SignedDepositToStake(6, 1, unit.ConvertIotxToRau(100).String(), nil, 100000, big.NewInt(10), identityset.PrivateKey(1))
+	require.NoError(err)
+	acts = append(acts, exec, deposit)
 
-	t.Log("Merkle root match pass\n")
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(acts...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	total, err := blk.TotalTransferAmount()
+	require.NoError(err)
+	require.Equal("9015000000001"+"000000000000000000", total.String())
+	require.Zero(expected.Cmp(total))
+	// the transfers only
+	require.Zero(new(big.Int).Sub(expected, value).Cmp(blk.CalculateTransferAmount()))
+
+	total, err = (&Block{}).TotalTransferAmount()
+	require.NoError(err)
+	require.Zero(total.Sign())
 }
 
-var (
-	pkBytes = identityset.PrivateKey(27).PublicKey().Bytes()
-	pbBlock = iotextypes.Block{
-		Header: &iotextypes.BlockHeader{
-			Core: &iotextypes.BlockHeaderCore{
-				Version:   version.ProtocolVersion,
-				Height:    123456789,
-				Timestamp: timestamppb.Now(),
-			},
-			ProducerPubkey: pkBytes,
-		},
-		Body: &iotextypes.BlockBody{
-			Actions: []*iotextypes.Action{
-				{
-					Core: &iotextypes.ActionCore{
-						Action: &iotextypes.ActionCore_Transfer{
-							Transfer: &iotextypes.Transfer{
-								Amount:    "100000000000000000",
-								Recipient: "alice",
-							},
-						},
-						Version: version.ProtocolVersion,
-						Nonce:   101,
-						ChainID: 1,
-					},
-					SenderPubKey: pkBytes,
-					Signature:    action.ValidSig,
-				},
-				{
-					Core: &iotextypes.ActionCore{
-						Action: &iotextypes.ActionCore_Execution{
-							Execution: &iotextypes.Execution{
-								Contract: "bob",
-								Amount:   "200000000000000000",
-								Data:     []byte{1, 2, 3, 4},
-							},
-						},
-						Version: version.ProtocolVersion,
-						Nonce:   102,
-						ChainID: 2,
-					},
-					SenderPubKey: pkBytes,
-					Signature:    action.ValidSig,
-				},
-			},
-		},
+func TestHashExcludesReceipts(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.NoError(blk.SetReceipts(makeReceipts(t, blk, 2)))
+	h := blk.HashBlock()
+	require.NoError(blk.SetReceipts(nil))
+	require.Equal(h, blk.HashBlock())
+
+	// a freshly loaded block has no receipts and no cached hash
+	raw, err := proto.Marshal(blk.ConvertToBlockPb())
+	require.NoError(err)
+	loaded, err := (&Deserializer{}).DeserializeBlock(raw)
+	require.NoError(err)
+	require.Nil(loaded.Receipts)
+	require.Equal(h, loaded.HashBlock())
+	require.Equal(h, loaded.HashHeader())
+	require.NoError(loaded.SetReceipts(makeReceipts(t, loaded, 3)))
+	require.Equal(h, loaded.HashBlock())
+	clone := loaded.Clone()
+	require.NoError(clone.SetReceipts(nil))
+	require.Equal(h, clone.HashBlock())
+}
+
+func TestExecutionCount(t *testing.T) {
+	require := require.New(t)
+
+	// makeBlock only has transfers
+	blk := makeBlock(t, 5)
+	require.False(blk.HasExecutions())
+	require.Zero(blk.ExecutionCount())
+	require.False((&Block{}).HasExecutions())
+
+	acts := append([]action.SealedEnvelope{}, blk.Actions...)
+	exec, err := action.SignedExecution(identityset.Address(2).String(), identityset.PrivateKey(1), 1, big.NewInt(0), 100000, big.NewInt(10), []byte{1})
+	require.NoError(err)
+	deploy, err := action.SignedExecution(action.EmptyAddress, identityset.PrivateKey(1), 2, big.NewInt(0), 100000, big.NewInt(10), []byte{1})
+	require.NoError(err)
+	acts = append(acts, exec, deploy)
+	mixed, err := NewBuilder(NewRunnableActionsBuilder().AddActions(acts...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.True(mixed.HasExecutions())
+	require.Equal(2, mixed.ExecutionCount())
+}
+
+func TestSizeBreakdown(t *testing.T) {
+	require := require.New(t)
+
+	var acts []action.SealedEnvelope
+	for i := 0; i < 6; i++ {
+		var (
+			selp action.SealedEnvelope
+			err  error
+		)
+		if i%3 == 0 {
+			selp, err = action.SignedExecution(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(0), 100000, big.NewInt(10), make([]byte, 2000))
+		} else {
+			selp, err = action.SignedTransfer(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(10))
+		}
+		require.NoError(err)
+		acts = append(acts, selp)
 	}
-)
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(acts...).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.NoError(blk.Finalize([]*endorsement.Endorsement{
+		endorsement.NewEndorsement(time.Now(), identityset.PrivateKey(1).PublicKey(), []byte("signature")),
+	}, time.Now()))
 
-func TestConvertFromBlockPb(t *testing.T) {
-	blk := Block{}
-	require.NoError(t, blk.ConvertFromBlockPb(&pbBlock))
+	sizes, err := blk.SizeBreakdown()
+	require.NoError(err)
+	require.Len(sizes, 5)
+	ser, err := blk.Serialize()
+	require.NoError(err)
+	total := 0
+	for _, size := range sizes {
+		require.Positive(size)
+		total += size
+	}
+	require.Equal(len(ser), total)
+	require.Greater(sizes["execution"], 2*2000)
+	require.Greater(sizes["execution"], sizes["transfer"])
+	// format version, then a tag and a length of at most 3 bytes for each part and action
+	require.LessOrEqual(sizes["framing"], 1+4*(3+len(acts)))
+}
 
-	txHash, err := blk.CalculateTxRoot()
-	require.NoError(t, err)
+func TestEstimatedStateDelta(t *testing.T) {
+	require := require.New(t)
 
-	blk.Header.txRoot = txHash
-	blk.Header.receiptRoot = hash.Hash256b(([]byte)("test"))
+	tsf, err := action.SignedTransfer(identityset.Address(1).String(), identityset.PrivateKey(1), 1, big.NewInt(1), nil, 100000, big.NewInt(10))
+	require.NoError(err)
+	exec, err := action.SignedExecution(identityset.Address(2).String(), identityset.PrivateKey(1), 2, big.NewInt(0), 100000, big.NewInt(10), []byte{1})
+	require.NoError(err)
+	deploy, err := action.SignedExecution(action.EmptyAddress, identityset.PrivateKey(1), 3, big.NewInt(0), 100000, big.NewInt(10), make([]byte, 100))
+	require.NoError(err)
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(tsf, exec, deploy).Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	expected := StateDeltaPerTransfer + StateDeltaPerExecution + StateDeltaPerDeployment + 100
+	delta, err := blk.EstimatedStateDelta()
+	require.NoError(err)
+	require.Equal(expected, delta)
+	require.NoError(blk.SetReceipts(makeReceipts(t, &blk, 2)))
+	delta, err = blk.EstimatedStateDelta()
+	require.NoError(err)
+	require.Equal(expected+6*StateDeltaPerLog, delta)
+	again, err := blk.EstimatedStateDelta()
+	require.NoError(err)
+	require.Equal(delta, again)
 
-	raw, err := blk.Serialize()
-	require.NoError(t, err)
+	// monotonic with the number of actions
+	var last int64
+	for _, n := range []int{0, 1, 2, 10, 50} {
+		blk := makeBlock(t, n)
+		require.NoError(blk.SetReceipts(makeReceipts(t, blk, 1)))
+		delta, err := blk.EstimatedStateDelta()
+		require.NoError(err)
+		if n > 0 {
+			require.Greater(delta, last)
+		}
+		last = delta
+	}
 
-	var newblk Block
-	err = newblk.Deserialize(raw)
-	require.NoError(t, err)
-	require.Equal(t, blk, newblk)
+	blk.Actions = append(blk.Actions, action.SealedEnvelope{})
+	_, err = blk.EstimatedStateDelta()
+	require.Error(err)
+}
+
+func TestValidateBasic(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(makeBlock(t, 3).ValidateBasic())
+	require.NoError(GenesisBlock().ValidateBasic())
+
+	for _, v := range []struct {
+		mutate func(*Block)
+		err    error
+	}{
+		{func(blk *Block) { blk.version = version.ProtocolVersion + 1 }, ErrUnsupportedVersion},
+		{func(blk *Block) { blk.timestamp = time.Time{} }, ErrZeroTimestamp},
+		{func(blk *Block) { blk.pubkey = nil }, ErrMissingProducer},
+		{func(blk *Block) { blk.blockSig = nil }, ErrMissingSignature},
+		{func(blk *Block) {
+			blk.Actions[1] = action.FakeSeal(blk.Actions[1].Envelope, blk.Actions[1].SrcPubkey())
+		}, ErrMissingSignature},
+		{func(blk *Block) {
+			blk.Actions[1] = action.AssembleSealedEnvelope(blk.Actions[1].Envelope, nil, blk.Actions[1].Signature())
+		}, ErrMissingSignature},
+		{func(blk *Block) { blk.Actions[0], blk.Actions[2] = blk.Actions[2], blk.Actions[0] }, ErrTxRootMismatch},
+	} {
+		blk := makeBlock(t, 3)
+		v.mutate(blk)
+		require.Equal(v.err, errors.Cause(blk.ValidateBasic()))
+	}
+}
+
+func TestSerializeActions(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 10)
+	blk.Receipts = makeReceipts(t, blk, 2)
+	ser, err := blk.SerializeActions()
+	require.NoError(err)
+	blkBytes, err := blk.Serialize()
+	require.NoError(err)
+	require.Less(len(ser), len(blkBytes))
+
+	acts, err := DeserializeActions(ser)
+	require.NoError(err)
+	require.Len(acts, len(blk.Actions))
+	hashes := make([]string, len(acts))
+	for i := range acts {
+		h, err := acts[i].Hash()
+		require.NoError(err)
+		hashes[i] = hex.EncodeToString(h[:])
+	}
+	require.Equal(blk.ActionHashs(), hashes)
+
+	_, err = DeserializeActions([]byte{0xff})
+	require.Error(err)
+}
+
+func TestActionByIndex(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	for i := range blk.Actions {
+		selp, err := blk.ActionByIndex(uint32(i))
+		require.NoError(err)
+		require.Equal(blk.Actions[i], selp)
+		h, err := selp.Hash()
+		require.NoError(err)
+		idx, ok := blk.ActionIndex(h)
+		require.True(ok)
+		require.EqualValues(i, idx)
+	}
+	for _, i := range []uint32{5, math.MaxUint32} {
+		_, err := blk.ActionByIndex(i)
+		require.Equal(ErrActionIndexOutOfRange, errors.Cause(err))
+	}
+	_, ok := blk.ActionIndex(hash.Hash256b([]byte("unknown")))
+	require.False(ok)
+
+	// the index follows the replaced actions
+	h, err := blk.Actions[4].Hash()
+	require.NoError(err)
+	blk.Actions = blk.Actions[:4]
+	_, ok = blk.ActionIndex(h)
+	require.False(ok)
+	_, err = blk.ActionByIndex(4)
+	require.Equal(ErrActionIndexOutOfRange, errors.Cause(err))
+}
+
+func TestDeserializeBatch(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		raws   [][]byte
+		hashes []hash.Hash256
+	)
+	for i := 0; i < 20; i++ {
+		blk := makeBlock(t, i%5+1)
+		raw, err := blk.Serialize()
+		require.NoError(err)
+		raws = append(raws, raw)
+		hashes = append(hashes, blk.HashBlock())
+	}
+	for _, workers := range []int{0, 1, 4, 100} {
+		blks, err := DeserializeBatch(raws, workers)
+		require.NoError(err)
+		require.Len(blks, len(raws))
+		for i, blk := range blks {
+			require.Equal(hashes[i], blk.HashBlock())
+		}
+	}
+	blks, err := DeserializeBatch(nil, 4)
+	require.NoError(err)
+	require.Empty(blks)
+
+	// the lowest index that failed is reported
+	bad := append([][]byte{}, raws...)
+	bad[7] = []byte{FormatVersion1, 0xff}
+	bad[15] = []byte{maxFormatVersion}
+	for _, workers := range []int{1, 4} {
+		blks, err = DeserializeBatch(bad, workers)
+		require.Error(err)
+		require.Nil(blks)
+		require.Contains(err.Error(), "block 7")
+	}
+}
+
+func BenchmarkDeserializeBatch(b *testing.B) {
+	raws := make([][]byte, 64)
+	for i := range raws {
+		raw, err := makeBlock(b, 200).Serialize()
+		require.NoError(b, err)
+		raws[i] = raw
+	}
+	b.Run("sequential", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, raw := range raws {
+				blk := &Block{}
+				if err := blk.Deserialize(raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	for _, workers := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if _, err := DeserializeBatch(raws, workers); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckNoDuplicateActions(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.NoError(blk.CheckNoDuplicateActions())
+	require.NoError((&Block{}).CheckNoDuplicateActions())
+
+	blk.Actions = append(blk.Actions, blk.Actions[2])
+	err := blk.CheckNoDuplicateActions()
+	require.Equal(ErrDuplicateAction, errors.Cause(err))
+	require.Contains(err.Error(), blk.ActionHashs()[2])
+	require.Contains(err.Error(), "index 2 and 5")
 }
 
 func TestBlockCompressionSize(t *testing.T) {
@@ -151,15 +2153,191 @@ func TestBlockCompressionSize(t *testing.T) {
 		require.NoError(t, err)
 		compressedBlkBytes, err := compress.CompGzip(blkBytes)
 		require.NoError(t, err)
+		snappyBlkBytes, err := compress.CompSnappy(blkBytes)
+		require.NoError(t, err)
 		log.L().Info(
 			"Compression result",
 			zap.Int("numActions", n),
 			zap.Int("before", len(blkBytes)),
 			zap.Int("after", len(compressedBlkBytes)),
+			zap.Int("snappy", len(snappyBlkBytes)),
 		)
 	}
 }
 
+func TestDeflateDictCompression(t *testing.T) {
+	require := require.New(t)
+
+	serializedBlock := func() []byte {
+		ser, err := makeBlock(t, 1).Serialize()
+		require.NoError(err)
+		return ser
+	}
+	var samples [][]byte
+	for i := 0; i < 32; i++ {
+		samples = append(samples, serializedBlock())
+	}
+	dict := compress.BuildDeflateDict(samples, compress.MaxDictSize)
+	require.NotEmpty(dict)
+	require.LessOrEqual(len(dict), compress.MaxDictSize)
+	require.Equal(dict, compress.BuildDeflateDict(samples, compress.MaxDictSize))
+	require.Len(compress.BuildDeflateDict(samples, 16), 16)
+	require.Empty(compress.BuildDeflateDict(samples[:1], compress.MaxDictSize))
+
+	ser := serializedBlock()
+	plain, err := compress.CompGzip(ser)
+	require.NoError(err)
+	withDict, err := compress.CompDeflateDict(ser, dict)
+	require.NoError(err)
+	log.L().Info(
+		"Dictionary compression result",
+		zap.Int("before", len(ser)),
+		zap.Int("gzip", len(plain)),
+		zap.Int("dict", len(withDict)),
+		zap.Int("dictSize", len(dict)),
+	)
+	require.Less(len(withDict), len(plain))
+	decompressed, err := compress.DecompDeflateDict(withDict, dict)
+	require.NoError(err)
+	require.Equal(ser, decompressed)
+
+	// the dictionary must be the same on both ends
+	_, err = compress.DecompDeflateDict(withDict, nil)
+	require.Error(err)
+	_, err = compress.DecompDeflateDict(withDict, dict[len(dict)-16:])
+	require.Error(err)
+
+	// no dictionary works as well
+	withoutDict, err := compress.CompDeflateDict(ser, nil)
+	require.NoError(err)
+	decompressed, err = compress.DecompDeflateDict(withoutDict, nil)
+	require.NoError(err)
+	require.Equal(ser, decompressed)
+}
+
+func TestCompressionRatio(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 100)
+	ser, err := blk.Serialize()
+	require.NoError(err)
+	for _, codec := range compress.Codecs {
+		compressed, err := codec.Compress(ser)
+		require.NoError(err)
+		ratio, err := blk.CompressionRatio(codec)
+		require.NoError(err)
+		require.Equal(float64(len(ser))/float64(len(compressed)), ratio)
+		require.Greater(ratio, 1.0)
+
+		empty := NewBuilder(NewRunnableActionsBuilder().Build()).SetTimestamp(time.Now()).BuildGenesis()
+		ratio, err = empty.CompressionRatio(codec)
+		require.NoError(err)
+		require.Equal(1.0, ratio)
+	}
+	_, err = blk.CompressionRatio("invalid")
+	require.Equal(compress.ErrUnsupportedCodec, errors.Cause(err))
+}
+
+func TestEstimateStorage(t *testing.T) {
+	require := require.New(t)
+
+	blocks := []*Block{makeBlock(t, 1), makeBlock(t, 10), makeBlock(t, 50)}
+	for _, codec := range compress.Codecs {
+		var wantSer, wantComp int64
+		for _, blk := range blocks {
+			ser, err := blk.Serialize()
+			require.NoError(err)
+			comp, err := codec.Compress(ser)
+			require.NoError(err)
+			wantSer += int64(len(ser))
+			wantComp += int64(len(comp))
+		}
+		ser, comp, err := EstimateStorage(blocks, codec)
+		require.NoError(err)
+		require.Equal(wantSer, ser)
+		require.Equal(wantComp, comp)
+		require.Less(comp, ser)
+	}
+
+	ser, comp, err := EstimateStorage(nil, compress.Gzip)
+	require.NoError(err)
+	require.Zero(ser)
+	require.Zero(comp)
+	_, _, err = EstimateStorage(blocks, "invalid")
+	require.Equal(compress.ErrUnsupportedCodec, errors.Cause(err))
+	_, _, err = EstimateStorage(append(blocks, nil), compress.Gzip)
+	require.Error(err)
+}
+
+func TestSerializeCompressed(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 10)
+	ser, err := blk.Serialize()
+	require.NoError(err)
+	tags := make(map[byte]struct{})
+	for _, codec := range compress.Codecs {
+		data, err := blk.SerializeCompressed(codec)
+		require.NoError(err)
+		// same as the two-step path, after the codec tag
+		compressed, err := codec.Compress(ser)
+		require.NoError(err)
+		require.Equal(compressed, data[1:])
+		tags[data[0]] = struct{}{}
+
+		newblk, err := DeserializeCompressed(data)
+		require.NoError(err)
+		require.True(blk.Equal(newblk))
+		require.Equal(blk.HashBlock(), newblk.HashBlock())
+	}
+	require.Len(tags, len(compress.Codecs))
+
+	_, err = blk.SerializeCompressed("invalid")
+	require.Equal(compress.ErrUnsupportedCodec, errors.Cause(err))
+	for _, data := range [][]byte{nil, {0}, {255, 1, 2}} {
+		_, err = DeserializeCompressed(data)
+		require.Equal(compress.ErrUnsupportedCodec, errors.Cause(err))
+	}
+	data, err := blk.SerializeCompressed(compress.Gzip)
+	require.NoError(err)
+	_, err = DeserializeCompressed(data[:len(data)/2])
+	require.Error(err)
+}
+
+func TestDecompGzipStreamOfBlocks(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "blocks.gz")
+	f, err := os.Create(path)
+	require.NoError(err)
+	var hashes []hash.Hash256
+	for _, n := range []int{1, 10, 100} {
+		blk := makeBlock(t, n)
+		blkBytes, err := blk.Serialize()
+		require.NoError(err)
+		compressed, err := compress.CompGzip(blkBytes)
+		require.NoError(err)
+		_, err = f.Write(compressed)
+		require.NoError(err)
+		hashes = append(hashes, blk.HashBlock())
+	}
+	require.NoError(f.Close())
+
+	f, err = os.Open(path)
+	require.NoError(err)
+	defer f.Close()
+	var read []hash.Hash256
+	require.NoError(compress.DecompGzipStream(f, func(blkBytes []byte) error {
+		var blk Block
+		if err := blk.Deserialize(blkBytes); err != nil {
+			return err
+		}
+		read = append(read, blk.HashBlock())
+		return nil
+	}))
+	require.Equal(hashes, read)
+}
+
 func BenchmarkBlockCompression(b *testing.B) {
 	for _, i := range []int{1, 10, 100, 1000, 2000} {
 		b.Run(fmt.Sprintf("numActions: %d", i), func(b *testing.B) {
@@ -194,6 +2372,28 @@ func BenchmarkBlockDecompression(b *testing.B) {
 	}
 }
 
+func BenchmarkSerializeCompressed(b *testing.B) {
+	for _, i := range []int{1, 100, 1000} {
+		blk := makeBlock(b, i)
+		b.Run(fmt.Sprintf("fused, numActions: %d", i), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				_, err := blk.SerializeCompressed(compress.Gzip)
+				require.NoError(b, err)
+			}
+		})
+		b.Run(fmt.Sprintf("two-step, numActions: %d", i), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				ser, err := blk.Serialize()
+				require.NoError(b, err)
+				_, err = compress.Codec(compress.Gzip).Compress(ser)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
 func makeBlock(tb testing.TB, n int) *Block {
 	rand.Seed(time.Now().Unix())
 	sevlps := make([]action.SealedEnvelope, 0)
@@ -235,6 +2435,31 @@ func makeBlock(tb testing.TB, n int) *Block {
 	return &blk
 }
 
+func makeReceipts(tb testing.TB, blk *Block, numLogs int) []*action.Receipt {
+	receipts := make([]*action.Receipt, 0, len(blk.Actions))
+	for i, selp := range blk.Actions {
+		h, err := selp.Hash()
+		require.NoError(tb, err)
+		r := &action.Receipt{
+			Status:      action.ReceiptStatusSuccess,
+			BlockHeight: blk.Height(),
+			ActionHash:  h,
+			GasConsumed: uint64(10000 + i),
+		}
+		for j := 0; j < numLogs; j++ {
+			r.AddLogs(&action.Log{
+				Address:     identityset.Address(j % identityset.Size()).String(),
+				Topics:      []hash.Hash256{hash.Hash256b([]byte(fmt.Sprintf("topic %d", j)))},
+				Data:        []byte("cd07d8a74179e032f030d9244"),
+				BlockHeight: blk.Height(),
+				ActionHash:  h,
+			})
+		}
+		receipts = append(receipts, r)
+	}
+	return receipts
+}
+
 func TestVerifyBlock(t *testing.T) {
 	require := require.New(t)
 
@@ -264,3 +2489,29 @@ func TestVerifyBlock(t *testing.T) {
 		require.Error(blk.VerifyTxRoot())
 	})
 }
+
+func TestActionSizes(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	sizes, err := blk.ActionSizes()
+	require.NoError(err)
+	require.Len(sizes, len(blk.Actions))
+	body, err := blk.Body.Serialize()
+	require.NoError(err)
+	total := 0
+	for _, selp := range blk.Actions {
+		h, err := selp.Hash()
+		require.NoError(err)
+		size, ok := sizes[h]
+		require.True(ok)
+		require.Positive(size)
+		// one tag byte and the length prefix for each action
+		total += 1 + protowire.SizeBytes(size)
+	}
+	require.Equal(len(body), total)
+
+	blk.Actions = append(blk.Actions, action.SealedEnvelope{})
+	_, err = blk.ActionSizes()
+	require.Error(err)
+}
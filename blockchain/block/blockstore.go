@@ -12,6 +12,7 @@ import (
 	"github.com/iotexproject/iotex-proto/golang/iotextypes"
 
 	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/pkg/compress"
 )
 
 type (
@@ -27,6 +28,16 @@ func (in *Store) Serialize() ([]byte, error) {
 	return proto.Marshal(in.ToProto())
 }
 
+// SerializeWithCompressor returns the serialized byte stream of Store compressed with codec, an unknown codec
+// returns compress.ErrUnsupportedCodec
+func (in *Store) SerializeWithCompressor(codec compress.Codec) ([]byte, error) {
+	ser, err := in.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return codec.Compress(ser)
+}
+
 // ToProto converts to proto message
 func (in *Store) ToProto() *iotextypes.BlockStore {
 	receipts := []*iotextypes.Receipt{}
@@ -69,6 +80,17 @@ func (in *Store) Deserialize(buf []byte) error {
 	return in.FromProto(pbStore)
 }
 
+// DeserializeWithCompressor decompresses the byte stream with codec and parses it into Store, an unknown codec
+// returns compress.ErrUnsupportedCodec. Snappy has no integrity check of its own, a corrupted stream is caught
+// by the tx root verification in FromProto instead
+func (in *Store) DeserializeWithCompressor(buf []byte, codec compress.Codec) error {
+	ser, err := codec.Decompress(buf)
+	if err != nil {
+		return err
+	}
+	return in.Deserialize(ser)
+}
+
 // DeserializeBlockStoresPb decode byte stream into BlockStores pb message
 func DeserializeBlockStoresPb(buf []byte) (*iotextypes.BlockStores, error) {
 	pbStores := &iotextypes.BlockStores{}
@@ -9,11 +9,13 @@ package block
 import (
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/iotexproject/go-pkgs/hash"
 
 	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/pkg/compress"
 	"github.com/iotexproject/iotex-core/test/identityset"
 	"github.com/iotexproject/iotex-core/testutil"
 )
@@ -46,6 +48,31 @@ func TestSerialize(t *testing.T) {
 	require.Equal(store1.Block.Header.blockSig, store.Block.Header.blockSig)
 }
 
+func TestSerializeWithCompressor(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 10)
+	store := &Store{Block: blk, Receipts: makeReceipts(t, blk, 2)}
+	for _, codec := range compress.Codecs {
+		ser, err := store.SerializeWithCompressor(codec)
+		require.NoError(err)
+		store1 := &Store{}
+		require.NoError(store1.DeserializeWithCompressor(ser, codec))
+		require.Equal(blk.HashBlock(), store1.Block.HashBlock())
+		require.Equal(len(store.Receipts), len(store1.Receipts))
+	}
+
+	// a block whose actions do not match the tx root is rejected after decompression
+	blk.Actions[0], blk.Actions[1] = blk.Actions[1], blk.Actions[0]
+	ser, err := store.SerializeWithCompressor(compress.Snappy)
+	require.NoError(err)
+	require.Equal(ErrTxRootMismatch, (&Store{}).DeserializeWithCompressor(ser, compress.Snappy))
+
+	_, err = store.SerializeWithCompressor("invalid")
+	require.Equal(compress.ErrUnsupportedCodec, errors.Cause(err))
+	require.Equal(compress.ErrUnsupportedCodec, errors.Cause((&Store{}).DeserializeWithCompressor(ser, "invalid")))
+}
+
 func makeStore() (*Store, error) {
 	receipts := []*action.Receipt{
 		{
@@ -11,11 +11,73 @@ import (
 
 	"github.com/iotexproject/go-pkgs/hash"
 	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/blockchain/genesis"
 )
 
+// MaxActionsPerBlock is the maximum number of actions a block body may carry when loaded from protobuf.
+// It defaults to the number of cheapest actions that fit into the default block gas limit.
+var MaxActionsPerBlock = genesis.Default.BlockGasLimit / action.TransferBaseIntrinsicGas
+
+// ErrTooManyActions indicates the block body carries more actions than MaxActionsPerBlock
+var ErrTooManyActions = errors.New("too many actions in block")
+
+// Field numbers of Block.body and BlockBody.actions, used to count the actions of a serialized block
+const (
+	blockBodyFieldNum   protowire.Number = 2
+	bodyActionsFieldNum protowire.Number = 1
+)
+
+// verifyActionCount returns ErrTooManyActions if the serialized BlockBody, or the serialized Block if inBlock,
+// carries more than MaxActionsPerBlock actions. It walks the field tags only, so an oversized body is rejected
+// before proto.Unmarshal allocates its actions.
+func verifyActionCount(buf []byte, inBlock bool) error {
+	numActs, err := countActions(buf, inBlock)
+	if err != nil {
+		return err
+	}
+	if numActs > MaxActionsPerBlock {
+		return errors.Wrapf(ErrTooManyActions, "%d actions exceeds limit %d", numActs, MaxActionsPerBlock)
+	}
+	return nil
+}
+
+// countActions counts the actions field of a serialized BlockBody, or of every body field of a serialized Block
+// if inBlock, since proto.Unmarshal merges repeated occurrences of a message field
+func countActions(buf []byte, inBlock bool) (uint64, error) {
+	var count uint64
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+		switch {
+		case typ == protowire.BytesType && inBlock && num == blockBodyFieldNum:
+			body, n := protowire.ConsumeBytes(buf)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			numActs, err := countActions(body, false)
+			if err != nil {
+				return 0, err
+			}
+			count += numActs
+		case typ == protowire.BytesType && !inBlock && num == bodyActionsFieldNum:
+			count++
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, buf); n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		buf = buf[n:]
+	}
+	return count, nil
+}
+
 // Body defines the struct of body
 type Body struct {
 	Actions []action.SealedEnvelope
@@ -39,7 +101,11 @@ func (b *Body) Serialize() ([]byte, error) {
 
 // LoadProto loads body from proto
 func (b *Body) LoadProto(pbBlock *iotextypes.BlockBody) error {
-	b.Actions = []action.SealedEnvelope{}
+	numActs := len(pbBlock.GetActions())
+	if uint64(numActs) > MaxActionsPerBlock {
+		return errors.Wrapf(ErrTooManyActions, "%d actions exceeds limit %d", numActs, MaxActionsPerBlock)
+	}
+	b.Actions = make([]action.SealedEnvelope, 0, numActs)
 	for _, actPb := range pbBlock.Actions {
 		act := action.SealedEnvelope{}
 		if err := act.LoadProto(actPb); err != nil {
@@ -53,6 +119,9 @@ func (b *Body) LoadProto(pbBlock *iotextypes.BlockBody) error {
 
 // Deserialize parses the byte stream into a Block
 func (b *Body) Deserialize(buf []byte) error {
+	if err := verifyActionCount(buf, false); err != nil {
+		return err
+	}
 	pb := iotextypes.BlockBody{}
 	if err := proto.Unmarshal(buf, &pb); err != nil {
 		return err
@@ -66,6 +135,38 @@ func (b *Body) CalculateTxRoot() (hash.Hash256, error) {
 	return calculateTxRoot(b.Actions)
 }
 
+// CalculateTxRootWith returns the Merkle root of all actions in this block, using hasher to compute the inner
+// nodes of the tree. Changing the hasher changes the tx root and breaks consensus, it is for experiments only.
+func (b *Body) CalculateTxRootWith(hasher func([]byte) hash.Hash256) (hash.Hash256, error) {
+	return calculateTxRootWith(b.Actions, hasher)
+}
+
+// TxRootExcluding returns the tx root the block would have without the action of hash h, the other actions
+// keep their relative order. Every occurrence of the action is removed. If the block does not include the
+// action, the tx root of the block is returned along with false.
+func (b *Body) TxRootExcluding(h hash.Hash256) (hash.Hash256, bool, error) {
+	var (
+		hashes   = make([]hash.Hash256, 0, len(b.Actions))
+		excluded bool
+	)
+	for _, selp := range b.Actions {
+		actHash, err := selp.Hash()
+		if err != nil {
+			return hash.ZeroHash256, false, err
+		}
+		if actHash == h {
+			excluded = true
+			continue
+		}
+		hashes = append(hashes, actHash)
+	}
+	root, err := CalculateTxRootFromHashes(hashes)
+	if err != nil {
+		return hash.ZeroHash256, false, err
+	}
+	return root, excluded, nil
+}
+
 // CalculateTransferAmount returns the calculated transfer amount in this block.
 func (b *Body) CalculateTransferAmount() *big.Int {
 	return calculateTransferAmount(b.Actions)
@@ -0,0 +1,76 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+
+	"github.com/iotexproject/iotex-core/action"
+)
+
+// BodyChunks splits the serialized body of the block along action boundaries, into chunks of at most maxBytes
+// bytes, except that an action larger than maxBytes takes a chunk of its own. Each chunk is the serialized body
+// of consecutive actions, so the concatenation of the chunks is the serialized body. ReassembleBody rebuilds the
+// actions from the chunks.
+func (b *Block) BodyChunks(maxBytes int) ([][]byte, error) {
+	if maxBytes <= 0 {
+		return nil, errors.Errorf("invalid chunk size %d", maxBytes)
+	}
+	var (
+		chunks [][]byte
+		chunk  = &iotextypes.BlockBody{}
+		size   int
+	)
+	flush := func() error {
+		if len(chunk.Actions) == 0 {
+			return nil
+		}
+		data, err := proto.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, data)
+		chunk, size = &iotextypes.BlockBody{}, 0
+		return nil
+	}
+	for i := range b.Actions {
+		act := b.Actions[i].Proto()
+		// the action is a length-delimited field 1 of the body
+		actSize := protowire.SizeTag(1) + protowire.SizeBytes(proto.Size(act))
+		if size > 0 && size+actSize > maxBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		chunk.Actions = append(chunk.Actions, act)
+		size += actSize
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// ReassembleBody rebuilds the actions of a block from the chunks returned by BodyChunks, in order
+func ReassembleBody(chunks [][]byte) ([]action.SealedEnvelope, error) {
+	var acts []action.SealedEnvelope
+	for i, data := range chunks {
+		body := Body{}
+		if err := body.Deserialize(data); err != nil {
+			return nil, errors.Wrapf(err, "failed to deserialize chunk %d", i)
+		}
+		if uint64(len(acts)+len(body.Actions)) > MaxActionsPerBlock {
+			return nil, errors.Wrapf(ErrTooManyActions, "chunk %d exceeds limit %d", i, MaxActionsPerBlock)
+		}
+		acts = append(acts, body.Actions...)
+	}
+	return acts, nil
+}
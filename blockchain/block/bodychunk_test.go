@@ -0,0 +1,62 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyChunks(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 1000)
+	body, err := blk.Body.Serialize()
+	require.NoError(err)
+	for _, maxBytes := range []int{4096, 1000, 1} {
+		chunks, err := blk.BodyChunks(maxBytes)
+		require.NoError(err)
+		require.Equal(body, bytes.Join(chunks, nil))
+		if maxBytes == 1 {
+			// every action is larger than the limit and takes a chunk of its own
+			require.Len(chunks, 1000)
+		} else {
+			require.Greater(len(chunks), len(body)/maxBytes)
+			for _, chunk := range chunks {
+				require.LessOrEqual(len(chunk), maxBytes)
+			}
+		}
+
+		acts, err := ReassembleBody(chunks)
+		require.NoError(err)
+		require.Len(acts, 1000)
+		hashes := make([]string, 0, len(acts))
+		for _, act := range acts {
+			h, err := act.Hash()
+			require.NoError(err)
+			hashes = append(hashes, hex.EncodeToString(h[:]))
+		}
+		require.Equal(blk.ActionHashs(), hashes)
+	}
+
+	chunks, err := makeBlock(t, 0).BodyChunks(4096)
+	require.NoError(err)
+	require.Empty(chunks)
+	acts, err := ReassembleBody(chunks)
+	require.NoError(err)
+	require.Empty(acts)
+
+	_, err = blk.BodyChunks(0)
+	require.Error(err)
+	chunks, err = blk.BodyChunks(4096)
+	require.NoError(err)
+	_, err = ReassembleBody([][]byte{chunks[0], chunks[1][:len(chunks[1])-1]})
+	require.Error(err)
+}
@@ -39,30 +39,35 @@ func NewBuilder(ra RunnableActions) *Builder {
 // SetTimestamp sets the block timestamp
 func (b *Builder) SetTimestamp(ts time.Time) *Builder {
 	b.blk.Header.timestamp = ts
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetHeight sets the block height
 func (b *Builder) SetHeight(h uint64) *Builder {
 	b.blk.Header.height = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetVersion sets the protocol version for block which is building.
 func (b *Builder) SetVersion(v uint32) *Builder {
 	b.blk.Header.version = v
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetPrevBlockHash sets the previous block hash for block which is building.
 func (b *Builder) SetPrevBlockHash(h hash.Hash256) *Builder {
 	b.blk.Header.prevBlockHash = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetDeltaStateDigest sets the new delta state digest after running actions included in this building block
 func (b *Builder) SetDeltaStateDigest(h hash.Hash256) *Builder {
 	b.blk.Header.deltaStateDigest = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
@@ -75,17 +80,30 @@ func (b *Builder) SetReceipts(receipts []*action.Receipt) *Builder {
 // SetReceiptRoot sets the receipt root after running actions included in this building block.
 func (b *Builder) SetReceiptRoot(h hash.Hash256) *Builder {
 	b.blk.Header.receiptRoot = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetLogsBloom sets the logs bloom filter value after running actions included in this building block.
 func (b *Builder) SetLogsBloom(f bloom.BloomFilter) *Builder {
 	b.blk.Header.logsBloom = f
+	b.blk.Header.resetHashCache()
+	return b
+}
+
+// SetExtraData sets the opaque data of the producer, at most MaxExtraDataSize bytes, which is checked by
+// SignAndBuild
+func (b *Builder) SetExtraData(data []byte) *Builder {
+	b.blk.Header.extraData = append([]byte(nil), data...)
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SignAndBuild signs and then builds a block.
 func (b *Builder) SignAndBuild(signerPrvKey crypto.PrivateKey) (Block, error) {
+	if len(b.blk.Header.extraData) > MaxExtraDataSize {
+		return Block{}, errors.Wrapf(ErrExtraDataTooLong, "%d bytes", len(b.blk.Header.extraData))
+	}
 	b.blk.Header.pubkey = signerPrvKey.PublicKey()
 	h := b.blk.Header.HashHeaderCore()
 	sig, err := signerPrvKey.Sign(h[:])
@@ -93,9 +111,22 @@ func (b *Builder) SignAndBuild(signerPrvKey crypto.PrivateKey) (Block, error) {
 		return Block{}, errors.New("failed to sign block")
 	}
 	b.blk.Header.blockSig = sig
+	b.blk.Header.resetHashCache()
 	return b.blk, nil
 }
 
+// BuildGenesis builds a height-0 block whose previous block hash is the zero hash.
+// The block is not signed, since a height-0 header carries neither producer nor signature,
+// and there is no parent to link to, so prev hash checks do not apply to it.
+func (b *Builder) BuildGenesis() Block {
+	b.blk.Header.height = 0
+	b.blk.Header.prevBlockHash = hash.ZeroHash256
+	b.blk.Header.pubkey = nil
+	b.blk.Header.blockSig = nil
+	b.blk.Header.resetHashCache()
+	return b.blk
+}
+
 // GetCurrentBlockHeader returns the current hash of Block Header Core
 func (b *Builder) GetCurrentBlockHeader() Header {
 	return b.blk.Header
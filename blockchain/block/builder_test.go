@@ -8,7 +8,9 @@ package block
 
 import (
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/iotexproject/go-pkgs/hash"
@@ -28,3 +30,107 @@ func TestBuilder(t *testing.T) {
 
 	require.True(t, nblk.VerifySignature())
 }
+
+func TestBuildGenesis(t *testing.T) {
+	require := require.New(t)
+	ra := NewRunnableActionsBuilder().Build()
+
+	blk := NewBuilder(ra).
+		SetHeight(5).
+		SetTimestamp(testutil.TimestampNow()).
+		SetPrevBlockHash(hash.Hash256b([]byte("parent"))).
+		BuildGenesis()
+	require.Zero(blk.Height())
+	require.Equal(hash.ZeroHash256, blk.PrevHash())
+	require.NoError(blk.VerifyTxRoot())
+
+	raw, err := blk.Serialize()
+	require.NoError(err)
+	var newblk Block
+	require.NoError(newblk.Deserialize(raw))
+	require.Zero(newblk.Height())
+	require.Equal(hash.ZeroHash256, newblk.PrevHash())
+	require.Nil(newblk.PublicKey())
+	require.Equal(blk.HashBlock(), newblk.HashBlock())
+}
+
+func TestBuilderExtraData(t *testing.T) {
+	require := require.New(t)
+
+	ts := testutil.TimestampNow()
+	builder := func(data []byte) *Builder {
+		return NewBuilder(NewRunnableActionsBuilder().Build()).
+			SetHeight(1).
+			SetTimestamp(ts).
+			SetPrevBlockHash(hash.Hash256b([]byte("parent"))).
+			SetExtraData(data)
+	}
+	blk, err := builder(nil).SignAndBuild(identityset.PrivateKey(29))
+	require.NoError(err)
+	require.Nil(blk.ExtraData())
+
+	// extra data round-trips, but is not part of the hash or the signed data
+	data := []byte("extra data of the producer")
+	extraBlk, err := builder(data).SignAndBuild(identityset.PrivateKey(29))
+	require.NoError(err)
+	require.Equal(data, extraBlk.ExtraData())
+	require.True(extraBlk.VerifySignature())
+	require.Equal(blk.HashBlock(), extraBlk.HashBlock())
+	require.Equal(blk.HashHeaderCore(), extraBlk.HashHeaderCore())
+
+	raw, err := extraBlk.Serialize()
+	require.NoError(err)
+	var newblk Block
+	require.NoError(newblk.Deserialize(raw))
+	require.Equal(data, newblk.ExtraData())
+	require.True(newblk.VerifySignature())
+	require.Equal(extraBlk.HashBlock(), newblk.HashBlock())
+	require.True(extraBlk.Equal(&newblk))
+	require.Equal(data, newblk.Clone().ExtraData())
+
+	cbor, err := extraBlk.MarshalCBOR()
+	require.NoError(err)
+	newblk = Block{}
+	require.NoError(newblk.UnmarshalCBOR(cbor))
+	require.Equal(extraBlk.HashBlock(), newblk.HashBlock())
+
+	// the maximum size is enforced at build time
+	_, err = builder(make([]byte, MaxExtraDataSize)).SignAndBuild(identityset.PrivateKey(29))
+	require.NoError(err)
+	_, err = builder(make([]byte, MaxExtraDataSize+1)).SignAndBuild(identityset.PrivateKey(29))
+	require.Equal(ErrExtraDataTooLong, errors.Cause(err))
+}
+
+func TestBuilderResetsHashCache(t *testing.T) {
+	require := require.New(t)
+
+	builder := NewBuilder(NewRunnableActionsBuilder().Build()).
+		SetHeight(1).
+		SetTimestamp(testutil.TimestampNow())
+	// a header above genesis needs a producer to be serialized
+	builder.blk.Header.pubkey = identityset.PrivateKey(0).PublicKey()
+	fresh := func() hash.Hash256 {
+		ser, err := builder.blk.Header.Serialize()
+		require.NoError(err)
+		return hash.Hash256b(ser)
+	}
+	for _, mutate := range []func(){
+		func() { builder.SetHeight(2) },
+		func() { builder.SetVersion(2) },
+		func() { builder.SetTimestamp(builder.blk.Timestamp().Add(time.Second)) },
+		func() { builder.SetPrevBlockHash(hash.Hash256b([]byte("parent"))) },
+		func() { builder.SetDeltaStateDigest(hash.Hash256b([]byte("delta"))) },
+		func() { builder.SetReceiptRoot(hash.Hash256b([]byte("receipts"))) },
+	} {
+		before := builder.blk.HashBlock()
+		mutate()
+		require.NotEqual(before, fresh())
+		require.Equal(fresh(), builder.blk.HashBlock())
+	}
+
+	tb := NewTestingBuilder().SetHeight(1)
+	tb.blk.Header.pubkey = identityset.PrivateKey(0).PublicKey()
+	before := tb.blk.HashBlock()
+	tb.SetHeight(2)
+	require.NotEqual(before, tb.blk.HashBlock())
+}
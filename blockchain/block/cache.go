@@ -0,0 +1,44 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/cache"
+	"github.com/iotexproject/go-pkgs/hash"
+)
+
+// Cache is a size-bounded LRU cache of deserialized blocks keyed by block hash, safe for concurrent use
+type Cache struct {
+	lru *cache.ThreadSafeLruCache
+}
+
+// NewCache creates a Cache holding at most capacity blocks, the least recently used block is evicted first
+func NewCache(capacity int) *Cache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Cache{lru: cache.NewThreadSafeLruCache(capacity)}
+}
+
+// Get returns the block with the given hash
+func (c *Cache) Get(h hash.Hash256) (*Block, bool) {
+	v, ok := c.lru.Get(h)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Block), true
+}
+
+// Put adds the block under the given hash
+func (c *Cache) Put(h hash.Hash256, blk *Block) {
+	c.lru.Add(h, blk)
+}
+
+// Len returns the number of blocks in the cache
+func (c *Cache) Len() int {
+	return c.lru.Len()
+}
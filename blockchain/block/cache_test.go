@@ -0,0 +1,87 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	require := require.New(t)
+
+	blks := make([]*Block, 4)
+	hashes := make([]hash.Hash256, 4)
+	for i := range blks {
+		blks[i] = makeBlock(t, i+1)
+		hashes[i] = blks[i].HashBlock()
+	}
+
+	c := NewCache(3)
+	for i := 0; i < 3; i++ {
+		c.Put(hashes[i], blks[i])
+	}
+	require.Equal(3, c.Len())
+	// touch block 0, so block 1 becomes the least recently used
+	blk, ok := c.Get(hashes[0])
+	require.True(ok)
+	require.Equal(blks[0], blk)
+	c.Put(hashes[3], blks[3])
+	require.Equal(3, c.Len())
+	_, ok = c.Get(hashes[1])
+	require.False(ok)
+	for _, i := range []int{0, 2, 3} {
+		blk, ok = c.Get(hashes[i])
+		require.True(ok)
+		require.Equal(blks[i], blk)
+	}
+
+	// concurrent access
+	c = NewCache(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			j := i % len(blks)
+			c.Put(hashes[j], blks[j])
+			if blk, ok := c.Get(hashes[j]); ok {
+				require.Equal(hashes[j], blk.HashBlock())
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(2, c.Len())
+}
+
+func BenchmarkCache(b *testing.B) {
+	blk := makeBlock(b, 100)
+	raw, err := blk.Serialize()
+	require.NoError(b, err)
+	h := blk.HashBlock()
+
+	b.Run("uncached", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			var blk Block
+			require.NoError(b, blk.Deserialize(raw))
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		c := NewCache(16)
+		for n := 0; n < b.N; n++ {
+			if _, ok := c.Get(h); ok {
+				continue
+			}
+			var blk Block
+			require.NoError(b, blk.Deserialize(raw))
+			c.Put(h, &blk)
+		}
+	})
+}
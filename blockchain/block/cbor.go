@@ -0,0 +1,491 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CBOR major types, see RFC 8949 section 3.1
+const (
+	cborUint   byte = 0
+	cborNegInt byte = 1
+	cborBytes  byte = 2
+	cborText   byte = 3
+	cborArray  byte = 4
+	cborMap    byte = 5
+
+	cborMaxDepth = 8
+)
+
+// ErrInvalidCBOR indicates the CBOR data is malformed or not in the canonical block layout
+var ErrInvalidCBOR = errors.New("invalid CBOR block")
+
+// MarshalCBOR encodes the block as a canonical CBOR map, following the core deterministic encoding
+// requirements of RFC 8949 section 4.2.1: definite lengths, shortest integer heads and map keys sorted
+// by their encoded bytes. Actions are embedded as their protobuf encoding, so the tx root can be
+// recomputed from the decoded block. Receipts are not part of the encoding.
+func (b *Block) MarshalCBOR() ([]byte, error) {
+	pb := b.ConvertToBlockPb()
+	hpb := pb.GetHeader()
+	core := hpb.GetCore()
+	header := map[string]interface{}{
+		"version":          uint64(core.GetVersion()),
+		"height":           core.GetHeight(),
+		"timestamp":        cborTimestamp(core.GetTimestamp()),
+		"prevBlockHash":    core.GetPrevBlockHash(),
+		"txRoot":           core.GetTxRoot(),
+		"deltaStateDigest": core.GetDeltaStateDigest(),
+		"receiptRoot":      core.GetReceiptRoot(),
+	}
+	if bloom := core.GetLogsBloom(); len(bloom) > 0 {
+		header["logsBloom"] = bloom
+	}
+	if pk := hpb.GetProducerPubkey(); len(pk) > 0 {
+		header["producerPubkey"] = pk
+	}
+	if sig := hpb.GetSignature(); len(sig) > 0 {
+		header["signature"] = sig
+	}
+	if len(b.extraData) > 0 {
+		header["extraData"] = b.extraData
+	}
+	actions := make([]interface{}, 0, len(pb.GetBody().GetActions()))
+	for _, act := range pb.GetBody().GetActions() {
+		actBytes, err := proto.Marshal(act)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, actBytes)
+	}
+	endorsements := make([]interface{}, 0, len(pb.GetFooter().GetEndorsements()))
+	for _, en := range pb.GetFooter().GetEndorsements() {
+		endorsements = append(endorsements, map[string]interface{}{
+			"timestamp": cborTimestamp(en.GetTimestamp()),
+			"endorser":  en.GetEndorser(),
+			"signature": en.GetSignature(),
+		})
+	}
+	var buf bytes.Buffer
+	if err := cborEncode(&buf, map[string]interface{}{
+		"header":  header,
+		"actions": actions,
+		"footer": map[string]interface{}{
+			"commitTime":   cborTimestamp(pb.GetFooter().GetTimestamp()),
+			"endorsements": endorsements,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR decodes a block encoded by MarshalCBOR and verifies its tx root. Only the canonical encoding is
+// accepted: besides non-shortest heads and unsorted map keys, which the decoder rejects, anything else that
+// MarshalCBOR would not produce for the decoded block, such as unknown keys, empty optional fields or
+// non-canonical action protobuf, is rejected by re-encoding the block, so a block has a single CBOR encoding.
+func (b *Block) UnmarshalCBOR(data []byte) error {
+	dec := cborDecoder{data: data}
+	v, err := dec.decode(0)
+	if err != nil {
+		return err
+	}
+	if dec.pos != len(data) {
+		return errors.Wrap(ErrInvalidCBOR, "trailing bytes")
+	}
+	root, err := cborMapOf(v, "block")
+	if err != nil {
+		return err
+	}
+	header, err := cborMapOf(root["header"], "header")
+	if err != nil {
+		return err
+	}
+	core := &iotextypes.BlockHeaderCore{}
+	version, err := cborUintOf(header["version"], "version")
+	if err != nil {
+		return err
+	}
+	if version > math.MaxUint32 {
+		return errors.Wrapf(ErrInvalidCBOR, "version %d overflows", version)
+	}
+	core.Version = uint32(version)
+	if core.Height, err = cborUintOf(header["height"], "height"); err != nil {
+		return err
+	}
+	if core.Timestamp, err = cborTimestampOf(header["timestamp"], "timestamp"); err != nil {
+		return err
+	}
+	for key, field := range map[string]*[]byte{
+		"prevBlockHash":    &core.PrevBlockHash,
+		"txRoot":           &core.TxRoot,
+		"deltaStateDigest": &core.DeltaStateDigest,
+		"receiptRoot":      &core.ReceiptRoot,
+	} {
+		if *field, err = cborBytesOf(header[key], key); err != nil {
+			return err
+		}
+	}
+	var (
+		hpb       = &iotextypes.BlockHeader{Core: core}
+		extraData []byte
+	)
+	for key, field := range map[string]*[]byte{
+		"logsBloom":      &core.LogsBloom,
+		"producerPubkey": &hpb.ProducerPubkey,
+		"signature":      &hpb.Signature,
+		"extraData":      &extraData,
+	} {
+		if _, ok := header[key]; !ok {
+			continue
+		}
+		if *field, err = cborBytesOf(header[key], key); err != nil {
+			return err
+		}
+	}
+	setExtensionFields(core, []extensionField{
+		{extraDataFieldNum, extraData},
+	})
+	actions, err := cborArrayOf(root["actions"], "actions")
+	if err != nil {
+		return err
+	}
+	body := &iotextypes.BlockBody{Actions: make([]*iotextypes.Action, 0, len(actions))}
+	for i, v := range actions {
+		actBytes, err := cborBytesOf(v, "action")
+		if err != nil {
+			return err
+		}
+		act := &iotextypes.Action{}
+		if err := proto.Unmarshal(actBytes, act); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal action %d", i)
+		}
+		body.Actions = append(body.Actions, act)
+	}
+	footer, err := cborMapOf(root["footer"], "footer")
+	if err != nil {
+		return err
+	}
+	fpb := &iotextypes.BlockFooter{Endorsements: []*iotextypes.Endorsement{}}
+	if fpb.Timestamp, err = cborTimestampOf(footer["commitTime"], "commitTime"); err != nil {
+		return err
+	}
+	endorsements, err := cborArrayOf(footer["endorsements"], "endorsements")
+	if err != nil {
+		return err
+	}
+	for _, v := range endorsements {
+		en, err := cborMapOf(v, "endorsement")
+		if err != nil {
+			return err
+		}
+		epb := &iotextypes.Endorsement{}
+		if epb.Timestamp, err = cborTimestampOf(en["timestamp"], "timestamp"); err != nil {
+			return err
+		}
+		if epb.Endorser, err = cborBytesOf(en["endorser"], "endorser"); err != nil {
+			return err
+		}
+		if epb.Signature, err = cborBytesOf(en["signature"], "signature"); err != nil {
+			return err
+		}
+		fpb.Endorsements = append(fpb.Endorsements, epb)
+	}
+	if err := b.ConvertFromBlockPb(&iotextypes.Block{
+		Header: hpb,
+		Body:   body,
+		Footer: fpb,
+	}); err != nil {
+		return err
+	}
+	b.Receipts = nil
+	if err := b.VerifyTxRoot(); err != nil {
+		return err
+	}
+	canonical, err := b.MarshalCBOR()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(canonical, data) {
+		return errors.Wrap(ErrInvalidCBOR, "not the canonical encoding of the block")
+	}
+	return nil
+}
+
+// cborTimestamp encodes a timestamp as [seconds, nanos]
+func cborTimestamp(ts *timestamppb.Timestamp) []interface{} {
+	return []interface{}{ts.GetSeconds(), uint64(ts.GetNanos())}
+}
+
+func cborTimestampOf(v interface{}, name string) (*timestamppb.Timestamp, error) {
+	arr, err := cborArrayOf(v, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(arr) != 2 {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s is not a [seconds, nanos] pair", name)
+	}
+	var seconds int64
+	switch s := arr[0].(type) {
+	case int64:
+		seconds = s
+	case uint64:
+		if s > math.MaxInt64 {
+			return nil, errors.Wrapf(ErrInvalidCBOR, "%s seconds overflow", name)
+		}
+		seconds = int64(s)
+	default:
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s seconds is %T", name, arr[0])
+	}
+	nanos, err := cborUintOf(arr[1], name)
+	if err != nil {
+		return nil, err
+	}
+	if nanos > math.MaxInt32 {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s nanos overflow", name)
+	}
+	ts := &timestamppb.Timestamp{Seconds: seconds, Nanos: int32(nanos)}
+	if err := ts.CheckValid(); err != nil {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s: %v", name, err)
+	}
+	return ts, nil
+}
+
+func cborMapOf(v interface{}, name string) (map[string]interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s is %T, expecting map", name, v)
+	}
+	return m, nil
+}
+
+func cborArrayOf(v interface{}, name string) ([]interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s is %T, expecting array", name, v)
+	}
+	return arr, nil
+}
+
+func cborBytesOf(v interface{}, name string) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidCBOR, "%s is %T, expecting bytes", name, v)
+	}
+	return b, nil
+}
+
+func cborUintOf(v interface{}, name string) (uint64, error) {
+	u, ok := v.(uint64)
+	if !ok {
+		return 0, errors.Wrapf(ErrInvalidCBOR, "%s is %T, expecting unsigned integer", name, v)
+	}
+	return u, nil
+}
+
+// cborEncodeHead writes the initial byte and argument of a data item using the shortest form
+func cborEncodeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		var b [2]byte
+		binary.BigEndian.PutUint16(b[:], uint16(arg))
+		buf.Write(b[:])
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(arg))
+		buf.Write(b[:])
+	default:
+		buf.WriteByte(major | 27)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], arg)
+		buf.Write(b[:])
+	}
+}
+
+// cborEncode writes v, which must be one of uint64, int64, []byte, string, []interface{} or
+// map[string]interface{}. Map keys are sorted by their encoded bytes.
+func cborEncode(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case uint64:
+		cborEncodeHead(buf, cborUint, v)
+	case int64:
+		if v >= 0 {
+			cborEncodeHead(buf, cborUint, uint64(v))
+		} else {
+			cborEncodeHead(buf, cborNegInt, uint64(-(v + 1)))
+		}
+	case []byte:
+		cborEncodeHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		cborEncodeHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		cborEncodeHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := cborEncode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		type entry struct {
+			key   []byte
+			value interface{}
+		}
+		entries := make([]entry, 0, len(v))
+		for k, item := range v {
+			var kb bytes.Buffer
+			cborEncodeHead(&kb, cborText, uint64(len(k)))
+			kb.WriteString(k)
+			entries = append(entries, entry{kb.Bytes(), item})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		cborEncodeHead(buf, cborMap, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			if err := cborEncode(buf, e.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.Errorf("unsupported CBOR value type %T", v)
+	}
+	return nil
+}
+
+// cborDecoder decodes the subset of CBOR written by cborEncode, rejecting integer heads that are not in the
+// shortest form and map keys that are not sorted by their encoded bytes
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decodeHead() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, errors.Wrap(ErrInvalidCBOR, "unexpected end of data")
+	}
+	major, info := d.data[d.pos]>>5, d.data[d.pos]&0x1f
+	d.pos++
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, errors.Wrapf(ErrInvalidCBOR, "unsupported additional information %d", info)
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.pos < size {
+		return 0, 0, errors.Wrap(ErrInvalidCBOR, "unexpected end of data")
+	}
+	var arg uint64
+	for _, c := range d.data[d.pos : d.pos+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.pos += size
+	// the argument must not fit into a shorter head
+	if min := [...]uint64{24, 1 << 8, 1 << 16, 1 << 32}[info-24]; arg < min {
+		return 0, 0, errors.Wrapf(ErrInvalidCBOR, "argument %d is not in the shortest form", arg)
+	}
+	return major, arg, nil
+}
+
+func (d *cborDecoder) decodeBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errors.Wrap(ErrInvalidCBOR, "unexpected end of data")
+	}
+	b := make([]byte, n)
+	copy(b, d.data[d.pos:])
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.Wrap(ErrInvalidCBOR, "nested too deep")
+	}
+	major, arg, err := d.decodeHead()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return arg, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.Wrap(ErrInvalidCBOR, "negative integer overflows")
+		}
+		return -int64(arg) - 1, nil
+	case cborBytes:
+		return d.decodeBytes(arg)
+	case cborText:
+		b, err := d.decodeBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborArray:
+		// every item takes at least one byte
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errors.Wrap(ErrInvalidCBOR, "unexpected end of data")
+		}
+		arr := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case cborMap:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, errors.Wrap(ErrInvalidCBOR, "unexpected end of data")
+		}
+		m := make(map[string]interface{}, arg)
+		var prevKey []byte
+		for i := uint64(0); i < arg; i++ {
+			start := d.pos
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, errors.Wrapf(ErrInvalidCBOR, "map key is %T, expecting text", k)
+			}
+			// strictly sorted keys are also distinct
+			encKey := d.data[start:d.pos]
+			if i > 0 && bytes.Compare(prevKey, encKey) >= 0 {
+				return nil, errors.Wrapf(ErrInvalidCBOR, "map key %s is duplicate or out of order", key)
+			}
+			prevKey = encKey
+			if m[key], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	default:
+		return nil, errors.Wrapf(ErrInvalidCBOR, "unsupported major type %d", major)
+	}
+}
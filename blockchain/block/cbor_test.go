@@ -0,0 +1,169 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/iotexproject/iotex-core/endorsement"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestBlockCBOR(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.NoError(blk.Finalize([]*endorsement.Endorsement{
+		endorsement.NewEndorsement(time.Now(), identityset.PrivateKey(1).PublicKey(), []byte("signature 1")),
+		endorsement.NewEndorsement(time.Now(), identityset.PrivateKey(2).PublicKey(), []byte("signature 2")),
+	}, time.Now()))
+	blk.Receipts = makeReceipts(t, blk, 2)
+
+	data, err := blk.MarshalCBOR()
+	require.NoError(err)
+	again, err := blk.MarshalCBOR()
+	require.NoError(err)
+	require.Equal(data, again)
+
+	var newblk Block
+	require.NoError(newblk.UnmarshalCBOR(data))
+	require.Nil(newblk.Receipts)
+	require.NoError(newblk.VerifyTxRoot())
+	blk.Receipts = nil
+	require.True(blk.Equal(&newblk))
+	require.Equal(blk.HashBlock(), newblk.HashBlock())
+	again, err = newblk.MarshalCBOR()
+	require.NoError(err)
+	require.Equal(data, again)
+
+	// genesis block has no producer
+	genesis := NewBuilder(NewRunnableActionsBuilder().Build()).SetTimestamp(time.Unix(0, 0)).BuildGenesis()
+	data, err = genesis.MarshalCBOR()
+	require.NoError(err)
+	newblk = Block{}
+	require.NoError(newblk.UnmarshalCBOR(data))
+	require.Nil(newblk.PublicKey())
+	require.Equal(genesis.HashBlock(), newblk.HashBlock())
+
+	// tampered tx root
+	blk.Header.txRoot = hash.Hash256b([]byte("tampered"))
+	data, err = blk.MarshalCBOR()
+	require.NoError(err)
+	require.Equal(ErrTxRootMismatch, errors.Cause(newblk.UnmarshalCBOR(data)))
+
+	// malformed data
+	for _, bad := range [][]byte{
+		nil,
+		{0xa0},       // empty map
+		{0x01},       // not a map
+		{0x5f},       // indefinite length bytes
+		{0x9b, 0xff}, // truncated array length
+		append(data, 0x00),
+	} {
+		require.Equal(ErrInvalidCBOR, errors.Cause(newblk.UnmarshalCBOR(bad)))
+	}
+}
+
+func TestBlockCBORNonCanonical(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 2)
+	data, err := blk.MarshalCBOR()
+	require.NoError(err)
+	require.NoError((&Block{}).UnmarshalCBOR(data))
+
+	// the top-level map of 3 entries with a 1-byte argument instead of the short form
+	require.Equal(byte(0xa3), data[0])
+	err = (&Block{}).UnmarshalCBOR(append([]byte{0xb8, 0x03}, data[1:]...))
+	require.Equal(ErrInvalidCBOR, errors.Cause(err))
+	require.Contains(err.Error(), "shortest form")
+
+	// valid CBOR that decodes to the same block is still rejected
+	reencode := func(mutate func(root map[string]interface{})) []byte {
+		dec := cborDecoder{data: data}
+		v, err := dec.decode(0)
+		require.NoError(err)
+		root := v.(map[string]interface{})
+		mutate(root)
+		var buf bytes.Buffer
+		require.NoError(cborEncode(&buf, root))
+		return buf.Bytes()
+	}
+	require.Equal(data, reencode(func(map[string]interface{}) {}))
+	for _, mutate := range []func(root map[string]interface{}){
+		// unknown key
+		func(root map[string]interface{}) { root["extra"] = uint64(1) },
+		// empty optional field
+		func(root map[string]interface{}) { root["header"].(map[string]interface{})["extraData"] = []byte{} },
+		// action protobuf with an unknown field
+		func(root map[string]interface{}) {
+			acts := root["actions"].([]interface{})
+			acts[0] = protowire.AppendVarint(protowire.AppendTag(acts[0].([]byte), 100, protowire.VarintType), 1)
+		},
+	} {
+		err := (&Block{}).UnmarshalCBOR(reencode(mutate))
+		require.Equal(ErrInvalidCBOR, errors.Cause(err))
+		require.Contains(err.Error(), "canonical")
+	}
+}
+
+func TestCBOREncode(t *testing.T) {
+	require := require.New(t)
+
+	for _, v := range []struct {
+		value   interface{}
+		encoded string
+	}{
+		{uint64(0), "00"},
+		{uint64(23), "17"},
+		{uint64(24), "1818"},
+		{uint64(1000), "1903e8"},
+		{uint64(1000000), "1a000f4240"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{int64(-1), "20"},
+		{int64(-1000), "3903e7"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{[]interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}}, "8201820203"},
+		// keys are sorted by encoded bytes, so shorter keys come first
+		{map[string]interface{}{"aa": uint64(2), "b": uint64(1), "a": uint64(0)}, "a3616100616201626161" + "02"},
+	} {
+		var buf bytes.Buffer
+		require.NoError(cborEncode(&buf, v.value))
+		require.Equal(v.encoded, hex.EncodeToString(buf.Bytes()))
+		dec := cborDecoder{data: buf.Bytes()}
+		decoded, err := dec.decode(0)
+		require.NoError(err)
+		require.Equal(v.value, decoded)
+	}
+	require.Error(cborEncode(&bytes.Buffer{}, 1))
+
+	for _, bad := range []string{
+		"1817",                  // 23 with a 1-byte argument
+		"1900ff",                // 255 with a 2-byte argument
+		"1a0000ffff",            // 65535 with a 4-byte argument
+		"1b00000000ffffffff",    // 2^32-1 with an 8-byte argument
+		"5801ff",                // 1-byte string with a 1-byte length
+		"a2616201616100",        // keys out of order
+		"a2616100616101",        // duplicate key
+		"a2626161006162" + "01", // longer key first
+	} {
+		raw, err := hex.DecodeString(bad)
+		require.NoError(err)
+		dec := cborDecoder{data: raw}
+		_, err = dec.decode(0)
+		require.Equal(ErrInvalidCBOR, errors.Cause(err), bad)
+	}
+}
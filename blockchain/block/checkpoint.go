@@ -0,0 +1,83 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"math"
+
+	"github.com/iotexproject/go-pkgs/crypto"
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint is a signature of a validator over a block hash. The signed payload is the block hash only, so
+// checkpoints of several validators over the same block can be aggregated and checked anywhere the hash is
+// known. Height is informational and is not covered by the signature.
+type Checkpoint struct {
+	Height       uint64
+	BlockHash    hash.Hash256
+	Signature    []byte
+	SignerPubKey crypto.PublicKey
+}
+
+// SignCheckpoint signs the hash of the block with priv
+func (b *Block) SignCheckpoint(priv crypto.PrivateKey) (*Checkpoint, error) {
+	h := b.HashBlock()
+	sig, err := priv.Sign(h[:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign checkpoint of block %d", b.Height())
+	}
+	return &Checkpoint{
+		Height:       b.Height(),
+		BlockHash:    h,
+		Signature:    sig,
+		SignerPubKey: priv.PublicKey(),
+	}, nil
+}
+
+// Verify verifies the signature of the checkpoint over its block hash
+func (c *Checkpoint) Verify() error {
+	if c.SignerPubKey == nil {
+		return errors.Wrapf(ErrMissingSignature, "checkpoint of block %d has no signer", c.Height)
+	}
+	if len(c.Signature) == 0 {
+		return errors.Wrapf(ErrMissingSignature, "checkpoint of block %d", c.Height)
+	}
+	if !c.SignerPubKey.Verify(c.BlockHash[:], c.Signature) {
+		return errors.Wrapf(ErrInvalidSignature, "checkpoint of block %d by %s", c.Height, c.SignerPubKey.Address())
+	}
+	return nil
+}
+
+// VerifyAgainstCheckpoint verifies the block chains back to a trusted checkpoint, through the headers of the
+// blocks between them, in ascending height order, which are empty if the block is the child of the checkpoint
+// block. The signature of the checkpoint is verified, then each header must follow the previous one by height
+// and by prev hash, starting from the checkpoint.
+func (b *Block) VerifyAgainstCheckpoint(cp *Checkpoint, parents []*Header) error {
+	if err := cp.Verify(); err != nil {
+		return err
+	}
+	return verifyHeaderChain(cp.Height, cp.BlockHash, append(append([]*Header(nil), parents...), &b.Header))
+}
+
+// verifyHeaderChain verifies each header follows the previous one by height and by prev hash, starting from the
+// block of the given height and hash
+func verifyHeaderChain(height uint64, blkHash hash.Hash256, headers []*Header) error {
+	for _, h := range headers {
+		if h == nil {
+			return errors.Wrapf(ErrParentHeightMismatch, "missing header after height %d", height)
+		}
+		if height == math.MaxUint64 || h.Height() != height+1 {
+			return errors.Wrapf(ErrParentHeightMismatch, "height %d, parent height %d", h.Height(), height)
+		}
+		if h.PrevHash() != blkHash {
+			return errors.Wrapf(ErrPrevHashMismatch, "block %d has prev hash %x, parent hash %x", h.Height(), h.PrevHash(), blkHash)
+		}
+		height, blkHash = h.Height(), h.HashBlock()
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	cp, err := blk.SignCheckpoint(identityset.PrivateKey(5))
+	require.NoError(err)
+	require.Equal(blk.Height(), cp.Height)
+	require.Equal(blk.HashBlock(), cp.BlockHash)
+	require.Equal(identityset.PrivateKey(5).PublicKey().Bytes(), cp.SignerPubKey.Bytes())
+	require.NoError(cp.Verify())
+
+	// checkpoints of several validators over the same block
+	other, err := blk.SignCheckpoint(identityset.PrivateKey(6))
+	require.NoError(err)
+	require.NoError(other.Verify())
+	require.Equal(cp.BlockHash, other.BlockHash)
+
+	// tampering with the hash invalidates the checkpoint
+	tampered := *cp
+	tampered.BlockHash[0]++
+	require.Equal(ErrInvalidSignature, errors.Cause(tampered.Verify()))
+	tampered = *cp
+	tampered.SignerPubKey = other.SignerPubKey
+	require.Equal(ErrInvalidSignature, errors.Cause(tampered.Verify()))
+	tampered = *cp
+	tampered.Signature = nil
+	require.Equal(ErrMissingSignature, errors.Cause(tampered.Verify()))
+	tampered = *cp
+	tampered.SignerPubKey = nil
+	require.Equal(ErrMissingSignature, errors.Cause(tampered.Verify()))
+	// the height is not signed
+	tampered = *cp
+	tampered.Height++
+	require.NoError(tampered.Verify())
+	require.NoError(cp.Verify())
+}
+
+func TestVerifyAgainstCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	// a chain of 5 blocks on top of the checkpoint block
+	ts := time.Now()
+	chain := []*Block{makeBlock(t, 1)}
+	for i := 1; i <= 5; i++ {
+		parent := chain[i-1]
+		blk, err := NewTestingBuilder().
+			SetHeight(parent.Height() + 1).
+			SetPrevBlockHash(parent.HashBlock()).
+			SetTimeStamp(ts.Add(time.Duration(i) * time.Second)).
+			AddActions(makeBlock(t, 1).Actions...).
+			SignAndBuild(identityset.PrivateKey(0))
+		require.NoError(err)
+		chain = append(chain, &blk)
+	}
+	cp, err := chain[0].SignCheckpoint(identityset.PrivateKey(5))
+	require.NoError(err)
+	headers := make([]*Header, len(chain))
+	for i, blk := range chain {
+		headers[i] = &blk.Header
+	}
+
+	tip := chain[5]
+	require.NoError(tip.VerifyAgainstCheckpoint(cp, headers[1:5]))
+	require.NoError(chain[1].VerifyAgainstCheckpoint(cp, nil))
+	require.NoError(chain[3].VerifyAgainstCheckpoint(cp, headers[1:3]))
+
+	// broken links
+	for _, c := range []struct {
+		parents []*Header
+		err     error
+	}{
+		// a header is missing
+		{[]*Header{headers[1], headers[3], headers[4]}, ErrParentHeightMismatch},
+		{headers[2:5], ErrParentHeightMismatch},
+		{headers[1:4], ErrParentHeightMismatch},
+		{[]*Header{headers[1], headers[2], nil, headers[4]}, ErrParentHeightMismatch},
+		// the checkpoint block itself is not a parent
+		{headers[0:5], ErrParentHeightMismatch},
+	} {
+		require.Equal(c.err, errors.Cause(tip.VerifyAgainstCheckpoint(cp, c.parents)))
+	}
+	// a header at the right height from another fork
+	fork, err := NewTestingBuilder().
+		SetHeight(chain[3].Height()).
+		SetPrevBlockHash(chain[2].HashBlock()).
+		SetTimeStamp(ts).
+		SignAndBuild(identityset.PrivateKey(1))
+	require.NoError(err)
+	err = tip.VerifyAgainstCheckpoint(cp, []*Header{headers[1], headers[2], &fork.Header, headers[4]})
+	require.Equal(ErrPrevHashMismatch, errors.Cause(err))
+	// a checkpoint of another block at the same height
+	other, err := makeBlock(t, 2).SignCheckpoint(identityset.PrivateKey(5))
+	require.NoError(err)
+	require.Equal(ErrPrevHashMismatch, errors.Cause(tip.VerifyAgainstCheckpoint(other, headers[1:5])))
+	// a checkpoint with an invalid signature
+	other.BlockHash = cp.BlockHash
+	require.Equal(ErrInvalidSignature, errors.Cause(tip.VerifyAgainstCheckpoint(other, headers[1:5])))
+}
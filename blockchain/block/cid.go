@@ -0,0 +1,56 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/base32"
+	"strings"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+)
+
+// cidPrefix is the multibase prefix of lowercase base32 without padding
+const cidPrefix = "b"
+
+var (
+	// ErrInvalidCID indicates the string is not a block identifier returned by CID
+	ErrInvalidCID = errors.New("invalid block CID")
+
+	// cidMultihashPrefix is the multihash code of blake2b-256 (0xb220) as a varint, followed by the digest length
+	cidMultihashPrefix = []byte{0xa0, 0xe4, 0x02, 0x20}
+
+	cidEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+)
+
+// CID returns a short, URL-safe identifier of the block. It is the multihash of the block hash, encoded in
+// lowercase base32 with the multibase prefix "b", so it maps one-to-one to the block hash, which ParseCID
+// recovers.
+func (b *Block) CID() (string, error) {
+	h := b.HashBlock()
+	return cidPrefix + cidEncoding.EncodeToString(append(append([]byte{}, cidMultihashPrefix...), h[:]...)), nil
+}
+
+// ParseCID returns the block hash of an identifier returned by CID
+func ParseCID(cid string) (hash.Hash256, error) {
+	if !strings.HasPrefix(cid, cidPrefix) {
+		return hash.ZeroHash256, errors.Wrapf(ErrInvalidCID, "%s has no multibase prefix", cid)
+	}
+	data, err := cidEncoding.DecodeString(cid[len(cidPrefix):])
+	if err != nil {
+		return hash.ZeroHash256, errors.Wrapf(ErrInvalidCID, "%s: %v", cid, err)
+	}
+	// the unused bits of the last character are not checked by the decoder, reject any non-canonical form
+	if cidEncoding.EncodeToString(data) != cid[len(cidPrefix):] {
+		return hash.ZeroHash256, errors.Wrapf(ErrInvalidCID, "%s is not canonical", cid)
+	}
+	if len(data) != len(cidMultihashPrefix)+len(hash.ZeroHash256) || !bytes.HasPrefix(data, cidMultihashPrefix) {
+		return hash.ZeroHash256, errors.Wrapf(ErrInvalidCID, "%s is not a blake2b-256 multihash", cid)
+	}
+	return hash.BytesToHash256(data[len(cidMultihashPrefix):]), nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCID(t *testing.T) {
+	require := require.New(t)
+
+	seen := make(map[string]struct{})
+	for _, n := range []int{0, 1, 2, 5} {
+		blk := makeBlock(t, n)
+		cid, err := blk.CID()
+		require.NoError(err)
+		require.Equal(url.PathEscape(cid), cid)
+		require.Equal("b", cid[:1])
+		h, err := ParseCID(cid)
+		require.NoError(err)
+		require.Equal(blk.HashBlock(), h)
+		seen[cid] = struct{}{}
+	}
+	require.Len(seen, 4)
+
+	cid, err := makeBlock(t, 1).CID()
+	require.NoError(err)
+	for _, invalid := range []string{
+		"",
+		cid[1:],
+		"B" + cid[1:],
+		cid[:len(cid)-1],
+		cid + "a",
+		cid[:len(cid)-1] + "1",
+		// the last character carries unused bits
+		cid[:len(cid)-1] + string(cid[len(cid)-1]+1),
+		"b" + cidEncoding.EncodeToString(make([]byte, 36)),
+	} {
+		h, err := ParseCID(invalid)
+		require.Equal(ErrInvalidCID, errors.Cause(err), invalid)
+		require.Equal(hash.ZeroHash256, h)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/iotexproject/iotex-address/address"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/pkg/log"
+)
+
+// ethBlock is the block object returned by eth_getBlockByNumber without transaction details
+type ethBlock struct {
+	Number           string   `json:"number"`
+	Hash             string   `json:"hash"`
+	ParentHash       string   `json:"parentHash"`
+	Nonce            string   `json:"nonce"`
+	Sha3Uncles       string   `json:"sha3Uncles"`
+	LogsBloom        string   `json:"logsBloom"`
+	TransactionsRoot string   `json:"transactionsRoot"`
+	StateRoot        string   `json:"stateRoot"`
+	ReceiptsRoot     string   `json:"receiptsRoot"`
+	Miner            string   `json:"miner"`
+	Difficulty       string   `json:"difficulty"`
+	TotalDifficulty  string   `json:"totalDifficulty"`
+	ExtraData        string   `json:"extraData"`
+	Size             string   `json:"size"`
+	GasLimit         string   `json:"gasLimit"`
+	GasUsed          string   `json:"gasUsed"`
+	Timestamp        string   `json:"timestamp"`
+	Transactions     []string `json:"transactions"`
+	Uncles           []string `json:"uncles"`
+}
+
+// ethTransaction is the transaction object returned by eth_getBlockByNumber with transaction details
+type ethTransaction struct {
+	Hash             string  `json:"hash"`
+	BlockHash        string  `json:"blockHash"`
+	BlockNumber      string  `json:"blockNumber"`
+	TransactionIndex string  `json:"transactionIndex"`
+	From             string  `json:"from"`
+	To               *string `json:"to"`
+	Value            string  `json:"value"`
+	Gas              string  `json:"gas"`
+	GasPrice         string  `json:"gasPrice"`
+	Nonce            string  `json:"nonce"`
+	Input            string  `json:"input"`
+}
+
+// ToEthJSON returns the block as the JSON object of eth_getBlockByNumber, with transaction hashes only. The
+// state root is the delta state digest, the gas used is the sum over the receipts attached to the block, and
+// fields without IoTeX equivalent are zero.
+func (b *Block) ToEthJSON() ([]byte, error) {
+	txRoot, err := b.CalculateTxRoot()
+	if err != nil {
+		return nil, err
+	}
+	var miner common.Address
+	if b.pubkey != nil {
+		miner = common.BytesToAddress(b.pubkey.Address().Bytes())
+	}
+	bloom := make([]byte, 256)
+	if b.logsBloom != nil {
+		bloom = b.logsBloom.Bytes()
+	}
+	ser, err := b.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]string, 0, len(b.Actions))
+	for i := range b.Actions {
+		h, err := b.Actions[i].Hash()
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, hexutil.Encode(h[:]))
+	}
+	var (
+		blkHash     = b.HashBlock()
+		prevHash    = b.PrevHash()
+		stateRoot   = b.DeltaStateDigest()
+		receiptRoot = b.ReceiptRoot()
+	)
+	return json.Marshal(&ethBlock{
+		Number:           hexutil.EncodeUint64(b.Height()),
+		Hash:             hexutil.Encode(blkHash[:]),
+		ParentHash:       hexutil.Encode(prevHash[:]),
+		Nonce:            hexutil.Encode(make([]byte, 8)),
+		Sha3Uncles:       hexutil.Encode(make([]byte, 32)),
+		LogsBloom:        hexutil.Encode(bloom),
+		TransactionsRoot: hexutil.Encode(txRoot[:]),
+		StateRoot:        hexutil.Encode(stateRoot[:]),
+		ReceiptsRoot:     hexutil.Encode(receiptRoot[:]),
+		Miner:            miner.Hex(),
+		Difficulty:       hexutil.EncodeUint64(0),
+		TotalDifficulty:  hexutil.EncodeUint64(0),
+		ExtraData:        hexutil.Encode(b.extraData),
+		Size:             hexutil.EncodeUint64(uint64(len(ser))),
+		GasLimit:         hexutil.EncodeUint64(0),
+		GasUsed:          hexutil.EncodeUint64(b.TotalGasConsumed()),
+		Timestamp:        hexutil.EncodeUint64(uint64(b.Timestamp().Unix())),
+		Transactions:     txs,
+		Uncles:           []string{},
+	})
+}
+
+// TransactionsEthJSON returns the transfers and executions of the block as the JSON array of transaction objects of
+// eth_getBlockByNumber. The sender is derived from the public key of the action, the recipient of a transfer and
+// the contract of an execution are the "to" address, which is null for a contract deployment, and the payload of
+// a transfer and the data of an execution are the input. Other actions have no Ethereum analog, they are omitted
+// and their indices are logged as a warning. The transaction index is the position of the action in the block.
+func (b *Block) TransactionsEthJSON() ([]byte, error) {
+	var (
+		blkHash = b.HashBlock()
+		txs     = []*ethTransaction{}
+		omitted []int
+	)
+	for i := range b.Actions {
+		selp := &b.Actions[i]
+		if selp.Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		var (
+			to     string
+			amount *big.Int
+			input  []byte
+		)
+		switch act := selp.Action().(type) {
+		case *action.Transfer:
+			to, amount, input = act.Recipient(), act.Amount(), act.Payload()
+		case *action.Execution:
+			to, amount, input = act.Contract(), act.Amount(), act.Data()
+		default:
+			omitted = append(omitted, i)
+			continue
+		}
+		if amount == nil {
+			amount = big.NewInt(0)
+		}
+		pk := selp.SrcPubkey()
+		if pk == nil {
+			return nil, errors.Wrapf(ErrMissingSignature, "action %d has no sender", i)
+		}
+		h, err := selp.Hash()
+		if err != nil {
+			return nil, err
+		}
+		tx := &ethTransaction{
+			Hash:             hexutil.Encode(h[:]),
+			BlockHash:        hexutil.Encode(blkHash[:]),
+			BlockNumber:      hexutil.EncodeUint64(b.Height()),
+			TransactionIndex: hexutil.EncodeUint64(uint64(i)),
+			From:             common.BytesToAddress(pk.Address().Bytes()).Hex(),
+			Value:            hexutil.EncodeBig(amount),
+			Gas:              hexutil.EncodeUint64(selp.GasLimit()),
+			GasPrice:         hexutil.EncodeBig(selp.GasPrice()),
+			Nonce:            hexutil.EncodeUint64(selp.Nonce()),
+			Input:            hexutil.Encode(input),
+		}
+		if to != action.EmptyAddress {
+			addr, err := address.FromString(to)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid recipient of action %d", i)
+			}
+			ethAddr := common.BytesToAddress(addr.Bytes()).Hex()
+			tx.To = &ethAddr
+		}
+		txs = append(txs, tx)
+	}
+	if len(omitted) > 0 {
+		log.L().Warn("Omitted actions without Ethereum analog",
+			zap.Uint64("height", b.Height()),
+			zap.Ints("indices", omitted))
+	}
+	return json.Marshal(txs)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/test/identityset"
+	"github.com/iotexproject/iotex-core/testutil"
+)
+
+func TestToEthJSON(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	blk.SetReceipts(makeReceipts(t, blk, 1))
+	data, err := blk.ToEthJSON()
+	require.NoError(err)
+
+	var obj map[string]interface{}
+	require.NoError(json.Unmarshal(data, &obj))
+	for _, field := range []string{
+		"number", "hash", "parentHash", "nonce", "sha3Uncles", "logsBloom", "transactionsRoot", "stateRoot",
+		"receiptsRoot", "miner", "difficulty", "totalDifficulty", "extraData", "size", "gasLimit", "gasUsed",
+		"timestamp", "transactions", "uncles",
+	} {
+		require.Contains(obj, field)
+	}
+	txRoot, err := blk.CalculateTxRoot()
+	require.NoError(err)
+	h := blk.HashBlock()
+	require.Equal("0x"+hex.EncodeToString(txRoot[:]), obj["transactionsRoot"])
+	require.Equal("0x"+hex.EncodeToString(h[:]), obj["hash"])
+	require.Equal("0x1", obj["number"])
+	require.Equal(fmt.Sprintf("0x%x", blk.Timestamp().Unix()), obj["timestamp"])
+	require.Equal(fmt.Sprintf("0x%x", blk.TotalGasConsumed()), obj["gasUsed"])
+	require.Equal(common.BytesToAddress(blk.PublicKey().Address().Bytes()).Hex(), obj["miner"])
+	require.Equal("0x0", obj["difficulty"])
+	require.Equal("0x0000000000000000", obj["nonce"])
+	require.Len(obj["logsBloom"], 2+512)
+	txs := obj["transactions"].([]interface{})
+	require.Len(txs, 3)
+	for i, tx := range txs {
+		require.Equal("0x"+blk.ActionHashs()[i], tx)
+	}
+	require.Empty(obj["uncles"])
+
+	// genesis block has no producer
+	genesis := NewBuilder(NewRunnableActionsBuilder().Build()).SetTimestamp(time.Unix(0, 0)).BuildGenesis()
+	data, err = genesis.ToEthJSON()
+	require.NoError(err)
+	obj = nil
+	require.NoError(json.Unmarshal(data, &obj))
+	require.Equal("0x0", obj["number"])
+	require.Equal(common.Address{}.Hex(), obj["miner"])
+	require.Empty(obj["transactions"])
+}
+
+func TestTransactionsEthJSON(t *testing.T) {
+	require := require.New(t)
+
+	data, err := (&Block{}).TransactionsEthJSON()
+	require.NoError(err)
+	require.Equal("[]", string(data))
+
+	tsf, err := action.SignedTransfer(identityset.Address(2).String(), identityset.PrivateKey(1), 1, big.NewInt(100), []byte("memo"), 100000, big.NewInt(10))
+	require.NoError(err)
+	// a staking action has no Ethereum analog
+	deposit, err := action.SignedDepositToStake(2, 1, "1000", nil, 100000, big.NewInt(10), identityset.PrivateKey(1))
+	require.NoError(err)
+	exec, err := action.SignedExecution(identityset.Address(3).String(), identityset.PrivateKey(4), 3, big.NewInt(0), 200000, big.NewInt(20), []byte{0xab, 0xcd})
+	require.NoError(err)
+	deploy, err := action.SignedExecution(action.EmptyAddress, identityset.PrivateKey(4), 4, big.NewInt(5), 300000, big.NewInt(20), []byte{0x60})
+	require.NoError(err)
+	blk, err := NewBuilder(NewRunnableActionsBuilder().AddActions(tsf, deposit, exec, deploy).Build()).
+		SetHeight(7).
+		SetTimestamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	data, err = blk.TransactionsEthJSON()
+	require.NoError(err)
+
+	var txs []map[string]interface{}
+	require.NoError(json.Unmarshal(data, &txs))
+	require.Len(txs, 3)
+	ethAddr := func(i int) string {
+		return common.BytesToAddress(identityset.Address(i).Bytes()).Hex()
+	}
+	blkHash := blk.HashBlock()
+	for i, expect := range []struct {
+		index                              int
+		from                               string
+		to                                 interface{}
+		value, gas, gasPrice, nonce, input string
+	}{
+		{0, ethAddr(1), ethAddr(2), "0x64", "0x186a0", "0xa", "0x1", "0x" + hex.EncodeToString([]byte("memo"))},
+		{2, ethAddr(4), ethAddr(3), "0x0", "0x30d40", "0x14", "0x3", "0xabcd"},
+		{3, ethAddr(4), nil, "0x5", "0x493e0", "0x14", "0x4", "0x60"},
+	} {
+		tx := txs[i]
+		require.Equal("0x"+blk.ActionHashs()[expect.index], tx["hash"])
+		require.Equal("0x"+hex.EncodeToString(blkHash[:]), tx["blockHash"])
+		require.Equal("0x7", tx["blockNumber"])
+		require.Equal(fmt.Sprintf("0x%x", expect.index), tx["transactionIndex"])
+		require.Equal(expect.from, tx["from"])
+		require.Equal(expect.to, tx["to"])
+		require.Equal(expect.value, tx["value"])
+		require.Equal(expect.gas, tx["gas"])
+		require.Equal(expect.gasPrice, tx["gasPrice"])
+		require.Equal(expect.nonce, tx["nonce"])
+		require.Equal(expect.input, tx["input"])
+	}
+}
@@ -9,6 +9,9 @@ package block
 import (
 	"time"
 
+	"github.com/iotexproject/go-pkgs/hash"
+	blake2b "github.com/minio/blake2b-simd"
+	"github.com/pkg/errors"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -16,6 +19,26 @@ import (
 	"github.com/iotexproject/iotex-proto/golang/iotextypes"
 )
 
+// ErrInvalidEndorsement indicates the endorsement does not endorse the block
+var ErrInvalidEndorsement = errors.New("invalid endorsement")
+
+// commitVote is the document the delegates endorse to commit a block, the same as the
+// consensus vote of topic COMMIT built by the RollDPoS scheme
+type commitVote hash.Hash256
+
+// Hash returns the hash of the vote
+func (v commitVote) Hash() ([]byte, error) {
+	ser, err := proto.Marshal(&iotextypes.ConsensusVote{
+		BlockHash: v[:],
+		Topic:     iotextypes.ConsensusVote_COMMIT,
+	})
+	if err != nil {
+		return nil, err
+	}
+	h := blake2b.Sum256(ser)
+	return h[:], nil
+}
+
 // Footer defines a set of proof of this block
 type Footer struct {
 	endorsements []*endorsement.Endorsement
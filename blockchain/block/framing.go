@@ -0,0 +1,65 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// MaxFrameSize is the maximum size of a block frame accepted by ReadFramed
+var MaxFrameSize uint32 = 32 << 20
+
+// ErrFrameTooLarge indicates a block frame exceeds MaxFrameSize
+var ErrFrameTooLarge = errors.New("block frame is too large")
+
+// frameLengthSize is the size of the big-endian length prefix of a block frame
+const frameLengthSize = 4
+
+// WriteFramed writes the block as serialized by SerializeForNetwork, prefixed by its length as a 4-byte
+// big-endian integer, so that several blocks can be sent over one stream
+func (b *Block) WriteFramed(w io.Writer) error {
+	ser, err := b.SerializeForNetwork()
+	if err != nil {
+		return err
+	}
+	if uint64(len(ser)) > math.MaxUint32 {
+		return errors.Wrapf(ErrFrameTooLarge, "block %d takes %d bytes", b.Height(), len(ser))
+	}
+	frame := make([]byte, frameLengthSize, frameLengthSize+len(ser))
+	binary.BigEndian.PutUint32(frame, uint32(len(ser)))
+	_, err = w.Write(append(frame, ser...))
+	return err
+}
+
+// ReadFramed reads a block written by WriteFramed. It returns io.EOF if r ends before a new frame, and rejects
+// a frame larger than MaxFrameSize before reading it.
+func ReadFramed(r io.Reader) (*Block, error) {
+	var length [frameLengthSize]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "failed to read frame length")
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > MaxFrameSize {
+		return nil, errors.Wrapf(ErrFrameTooLarge, "%d bytes exceeds limit %d", size, MaxFrameSize)
+	}
+	ser := make([]byte, size)
+	if _, err := io.ReadFull(r, ser); err != nil {
+		return nil, errors.Wrap(err, "failed to read frame")
+	}
+	blk := &Block{}
+	if err := blk.DeserializeFromNetwork(ser); err != nil {
+		return nil, err
+	}
+	return blk, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramed(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		buf  bytes.Buffer
+		blks []*Block
+	)
+	for _, n := range []int{1, 5, 20} {
+		blk := makeBlock(t, n)
+		blk.Receipts = makeReceipts(t, blk, 1)
+		require.NoError(blk.WriteFramed(&buf))
+		blk.Receipts = nil
+		blks = append(blks, blk)
+	}
+	stream := bytes.NewReader(buf.Bytes())
+	for _, blk := range blks {
+		read, err := ReadFramed(stream)
+		require.NoError(err)
+		require.True(blk.Equal(read))
+	}
+	_, err := ReadFramed(stream)
+	require.Equal(io.EOF, err)
+
+	// truncated frames
+	_, err = ReadFramed(bytes.NewReader(buf.Bytes()[:2]))
+	require.Error(err)
+	require.NotEqual(io.EOF, err)
+	_, err = ReadFramed(bytes.NewReader(buf.Bytes()[:10]))
+	require.Error(err)
+
+	// a frame over the limit is rejected before its content is read
+	defer func(size uint32) { MaxFrameSize = size }(MaxFrameSize)
+	MaxFrameSize = 16
+	_, err = ReadFramed(bytes.NewReader(buf.Bytes()))
+	require.Equal(ErrFrameTooLarge, errors.Cause(err))
+	_, err = ReadFramed(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+	require.Equal(ErrFrameTooLarge, errors.Cause(err))
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync/atomic"
+	"time"
+
+	"github.com/iotexproject/go-pkgs/bloom"
+	"github.com/iotexproject/go-pkgs/crypto"
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/endorsement"
+)
+
+type (
+	// gobBlock is the gob encoding of a block. The header and footer fields are carried as is, the tx root
+	// included, so decoding does not recompute anything; actions and receipts keep their own serialization
+	// since their fields are not accessible from this package.
+	gobBlock struct {
+		Version          uint32
+		Height           uint64
+		Timestamp        time.Time
+		PrevBlockHash    hash.Hash256
+		TxRoot           hash.Hash256
+		DeltaStateDigest hash.Hash256
+		ReceiptRoot      hash.Hash256
+		LogsBloom        []byte
+		BlockSig         []byte
+		Pubkey           []byte
+		ExtraData        []byte
+		Actions          [][]byte
+		Endorsements     []gobEndorsement
+		CommitTime       time.Time
+		// HasReceipts tells nil receipts from empty ones, which gob does not
+		HasReceipts bool
+		Receipts    [][]byte
+	}
+
+	gobEndorsement struct {
+		Timestamp time.Time
+		Endorser  []byte
+		Signature []byte
+	}
+)
+
+// GobEncode implements gob.GobEncoder, it encodes the block along with its receipts. The encoding is meant
+// for local caches and is not stable across versions, use Serialize for anything persisted or sent to peers.
+func (b *Block) GobEncode() ([]byte, error) {
+	gb := gobBlock{
+		Version:          b.version,
+		Height:           b.height,
+		Timestamp:        b.timestamp,
+		PrevBlockHash:    b.prevBlockHash,
+		TxRoot:           b.txRoot,
+		DeltaStateDigest: b.deltaStateDigest,
+		ReceiptRoot:      b.receiptRoot,
+		BlockSig:         b.blockSig,
+		ExtraData:        b.extraData,
+		Actions:          make([][]byte, 0, len(b.Actions)),
+		CommitTime:       b.commitTime,
+		HasReceipts:      b.Receipts != nil,
+	}
+	if b.logsBloom != nil {
+		gb.LogsBloom = b.logsBloom.Bytes()
+	}
+	if b.pubkey != nil {
+		gb.Pubkey = b.pubkey.Bytes()
+	}
+	for i := range b.Actions {
+		ser, err := proto.Marshal(b.Actions[i].Proto())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to serialize action %d", i)
+		}
+		gb.Actions = append(gb.Actions, ser)
+	}
+	for _, en := range b.endorsements {
+		gb.Endorsements = append(gb.Endorsements, gobEndorsement{
+			Timestamp: en.Timestamp(),
+			Endorser:  en.Endorser().Bytes(),
+			Signature: en.Signature(),
+		})
+	}
+	for i, r := range b.Receipts {
+		ser, err := r.Serialize()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to serialize receipt %d", i)
+		}
+		gb.Receipts = append(gb.Receipts, ser)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&gb); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, it decodes the data produced by GobEncode
+func (b *Block) GobDecode(data []byte) error {
+	var gb gobBlock
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&gb); err != nil {
+		return errors.Wrap(err, "invalid gob encoded block")
+	}
+	blk := Block{
+		Header: Header{
+			version:          gb.Version,
+			height:           gb.Height,
+			timestamp:        gb.Timestamp,
+			prevBlockHash:    gb.PrevBlockHash,
+			txRoot:           gb.TxRoot,
+			deltaStateDigest: gb.DeltaStateDigest,
+			receiptRoot:      gb.ReceiptRoot,
+			blockSig:         gb.BlockSig,
+			extraData:        gb.ExtraData,
+		},
+		Body:   Body{Actions: make([]action.SealedEnvelope, len(gb.Actions))},
+		Footer: Footer{commitTime: gb.CommitTime},
+	}
+	if gb.LogsBloom != nil {
+		bf, err := bloom.NewBloomFilterLegacy(2048, 3)
+		if err != nil {
+			return err
+		}
+		if err := bf.FromBytes(gb.LogsBloom); err != nil {
+			return err
+		}
+		blk.logsBloom = bf
+	}
+	if len(gb.Pubkey) > 0 {
+		pk, err := crypto.BytesToPublicKey(gb.Pubkey)
+		if err != nil {
+			return err
+		}
+		blk.pubkey = pk
+	}
+	for i, ser := range gb.Actions {
+		actPb := &iotextypes.Action{}
+		if err := proto.Unmarshal(ser, actPb); err != nil {
+			return errors.Wrapf(err, "failed to deserialize action %d", i)
+		}
+		if err := blk.Actions[i].LoadProto(actPb); err != nil {
+			return errors.Wrapf(err, "failed to load action %d", i)
+		}
+	}
+	for _, en := range gb.Endorsements {
+		pk, err := crypto.BytesToPublicKey(en.Endorser)
+		if err != nil {
+			return err
+		}
+		blk.endorsements = append(blk.endorsements, endorsement.NewEndorsement(en.Timestamp, pk, en.Signature))
+	}
+	if gb.HasReceipts {
+		blk.Receipts = make([]*action.Receipt, len(gb.Receipts))
+		for i, ser := range gb.Receipts {
+			blk.Receipts[i] = &action.Receipt{}
+			if err := blk.Receipts[i].Deserialize(ser); err != nil {
+				return errors.Wrapf(err, "failed to deserialize receipt %d", i)
+			}
+		}
+	}
+	if b.Sealed() {
+		return ErrBlockSealed
+	}
+	b.Header = blk.Header
+	b.Body = blk.Body
+	b.Footer = blk.Footer
+	b.actionIdx = atomic.Value{}
+	b.senderIdx = atomic.Value{}
+	return b.SetReceipts(blk.Receipts)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/endorsement"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestBlockGob(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	require.NoError(blk.Finalize([]*endorsement.Endorsement{
+		endorsement.NewEndorsement(time.Now(), identityset.PrivateKey(1).PublicKey(), []byte("signature 1")),
+	}, time.Now()))
+
+	for _, receipts := range [][]*action.Receipt{nil, {}, makeReceipts(t, blk, 2)} {
+		require.NoError(blk.SetReceipts(receipts))
+		var buf bytes.Buffer
+		require.NoError(gob.NewEncoder(&buf).Encode(blk))
+		newblk := &Block{}
+		require.NoError(gob.NewDecoder(&buf).Decode(newblk))
+		require.True(blk.Equal(newblk))
+		require.Equal(blk.HashBlock(), newblk.HashBlock())
+		require.Equal(receipts == nil, newblk.Receipts == nil)
+		require.Equal(len(receipts), len(newblk.Receipts))
+	}
+
+	// a block is decoded as a field of a cached struct as well
+	type cached struct {
+		Block *Block
+		Added time.Time
+	}
+	var buf bytes.Buffer
+	require.NoError(gob.NewEncoder(&buf).Encode(&cached{Block: blk, Added: time.Now()}))
+	var entry cached
+	require.NoError(gob.NewDecoder(&buf).Decode(&entry))
+	require.True(blk.Equal(entry.Block))
+
+	// the tx root is carried as is, not recomputed from the actions
+	blk.txRoot = hash.Hash256b([]byte("not the tx root"))
+	blk.resetHashCache()
+	data, err := blk.GobEncode()
+	require.NoError(err)
+	newblk := &Block{}
+	require.NoError(newblk.GobDecode(data))
+	require.Equal(blk.TxRoot(), newblk.TxRoot())
+	require.Equal(blk.HashBlock(), newblk.HashBlock())
+
+	require.Error((&Block{}).GobDecode(nil))
+	require.Error((&Block{}).GobDecode([]byte{2}))
+}
@@ -7,6 +7,8 @@
 package block
 
 import (
+	"bytes"
+	"sync/atomic"
 	"time"
 
 	"github.com/iotexproject/go-pkgs/bloom"
@@ -15,6 +17,7 @@ import (
 	"github.com/iotexproject/iotex-proto/golang/iotextypes"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -35,13 +38,31 @@ type Header struct {
 	logsBloom        bloom.BloomFilter // bloom filter for all contract events in this block
 	blockSig         []byte            // block signature
 	pubkey           crypto.PublicKey  // block producer's public key
+	extraData        []byte            // opaque data set by the producer, optional
+
+	hashCache atomic.Value // memoized hash of the header
 }
 
+// Field numbers of the optional fields in BlockHeaderCore. iotex-proto does not define them yet, so they are
+// written as unknown fields, which proto.Marshal appends after the known fields and proto.Unmarshal keeps.
+// A header without optional fields has no unknown field and serializes as before.
+//
+// Since a peer or tool may drop or reorder unknown fields, the optional fields are left out of the header hash
+// and of the data signed by the producer: they travel with the header but do not affect consensus. A relay can
+// strip or alter them, so extra data is informational only.
+const (
+	extraDataFieldNum protowire.Number = 102
+)
+
+// MaxExtraDataSize is the maximum size of the extra data of a block
+const MaxExtraDataSize = 32
+
 // Errors
 var (
 	ErrTxRootMismatch      = errors.New("transaction merkle root does not match")
 	ErrDeltaStateMismatch  = errors.New("delta state digest doesn't match")
 	ErrReceiptRootMismatch = errors.New("receipt root hash does not match")
+	ErrExtraDataTooLong    = errors.New("block extra data is too long")
 )
 
 // Version returns the version of this block.
@@ -68,7 +89,12 @@ func (h *Header) PublicKey() crypto.PublicKey { return h.pubkey }
 // ReceiptRoot returns the receipt root after apply this block
 func (h *Header) ReceiptRoot() hash.Hash256 { return h.receiptRoot }
 
+// ExtraData returns the opaque data set by the producer, or nil if the block has none
+func (h *Header) ExtraData() []byte { return h.extraData }
+
 // HashBlock return the hash of this block (actually hash of block header)
+// Receipts are derived by executing the block and never affect its hash, only the receipt root and the logs
+// bloom of the header, which are set when the block is built, are part of it.
 func (h *Header) HashBlock() hash.Hash256 { return h.HashHeader() }
 
 // LogsBloomfilter return the bloom filter for all contract log events
@@ -76,8 +102,17 @@ func (h *Header) LogsBloomfilter() bloom.BloomFilter { return h.logsBloom }
 
 // BlockHeaderProto returns BlockHeader proto.
 func (h *Header) BlockHeaderProto() *iotextypes.BlockHeader {
+	return h.headerProto(h.BlockHeaderCoreProto())
+}
+
+// hashedHeaderProto returns the BlockHeader proto without the optional fields, which is what HashHeader hashes
+func (h *Header) hashedHeaderProto() *iotextypes.BlockHeader {
+	return h.headerProto(h.hashedCoreProto())
+}
+
+func (h *Header) headerProto(core *iotextypes.BlockHeaderCore) *iotextypes.BlockHeader {
 	header := iotextypes.BlockHeader{
-		Core: h.BlockHeaderCoreProto(),
+		Core: core,
 	}
 
 	if h.height > 0 {
@@ -87,8 +122,15 @@ func (h *Header) BlockHeaderProto() *iotextypes.BlockHeader {
 	return &header
 }
 
-// BlockHeaderCoreProto returns BlockHeaderCore proto.
+// BlockHeaderCoreProto returns BlockHeaderCore proto, including the optional fields.
 func (h *Header) BlockHeaderCoreProto() *iotextypes.BlockHeaderCore {
+	core := h.hashedCoreProto()
+	setExtensionFields(core, h.extensionFields())
+	return core
+}
+
+// hashedCoreProto returns the BlockHeaderCore proto without the optional fields, which is what the producer signs
+func (h *Header) hashedCoreProto() *iotextypes.BlockHeaderCore {
 	ts := timestamppb.New(h.timestamp)
 	header := iotextypes.BlockHeaderCore{
 		Version:          h.version,
@@ -105,14 +147,78 @@ func (h *Header) BlockHeaderCoreProto() *iotextypes.BlockHeaderCore {
 	return &header
 }
 
+// extensionField is an optional field of the header core, written as an unknown field
+type extensionField struct {
+	num   protowire.Number
+	value []byte
+}
+
+// extensionFields returns the optional fields of the header in field number order
+func (h *Header) extensionFields() []extensionField {
+	return []extensionField{
+		{extraDataFieldNum, h.extraData},
+	}
+}
+
+// setExtensionFields writes the non-empty fields as unknown fields of the header core
+func setExtensionFields(pb *iotextypes.BlockHeaderCore, fields []extensionField) {
+	var raw []byte
+	for _, f := range fields {
+		if len(f.value) > 0 {
+			raw = protowire.AppendTag(raw, f.num, protowire.BytesType)
+			raw = protowire.AppendBytes(raw, f.value)
+		}
+	}
+	if len(raw) > 0 {
+		pb.ProtoReflect().SetUnknown(raw)
+	}
+}
+
+// loadExtensionFields reads the optional fields from the unknown fields of the header core, other unknown
+// fields are ignored
+func (h *Header) loadExtensionFields(pb *iotextypes.BlockHeaderCore) error {
+	h.extraData = nil
+	fields := map[protowire.Number]*[]byte{
+		extraDataFieldNum: &h.extraData,
+	}
+	raw := []byte(pb.ProtoReflect().GetUnknown())
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		raw = raw[n:]
+		if field, ok := fields[num]; ok && typ == protowire.BytesType {
+			var v []byte
+			if v, n = protowire.ConsumeBytes(raw); n < 0 {
+				return protowire.ParseError(n)
+			}
+			*field = append([]byte(nil), v...)
+		} else if n = protowire.ConsumeFieldValue(num, typ, raw); n < 0 {
+			return protowire.ParseError(n)
+		}
+		raw = raw[n:]
+	}
+	if len(h.extraData) > MaxExtraDataSize {
+		return errors.Wrapf(ErrExtraDataTooLong, "%d bytes", len(h.extraData))
+	}
+	return nil
+}
+
 // LoadFromBlockHeaderProto loads from protobuf
 func (h *Header) LoadFromBlockHeaderProto(pb *iotextypes.BlockHeader) error {
+	h.resetHashCache()
 	if err := h.loadFromBlockHeaderCoreProto(pb.GetCore()); err != nil {
 		return err
 	}
 	sig := pb.GetSignature()
 	h.blockSig = make([]byte, len(sig))
 	copy(h.blockSig, sig)
+	if h.height == 0 && len(pb.GetProducerPubkey()) == 0 {
+		// a genesis header has no producer, see BlockHeaderProto()
+		h.pubkey = nil
+		return nil
+	}
 	pubKey, err := crypto.BytesToPublicKey(pb.GetProducerPubkey())
 	if err != nil {
 		return err
@@ -133,6 +239,9 @@ func (h *Header) loadFromBlockHeaderCoreProto(pb *iotextypes.BlockHeaderCore) er
 	copy(h.txRoot[:], pb.GetTxRoot())
 	copy(h.deltaStateDigest[:], pb.GetDeltaStateDigest())
 	copy(h.receiptRoot[:], pb.GetReceiptRoot())
+	if err := h.loadExtensionFields(pb); err != nil {
+		return err
+	}
 	var err error
 	if pb.GetLogsBloom() != nil {
 		h.logsBloom, err = bloom.NewBloomFilterLegacy(2048, 3)
@@ -146,9 +255,9 @@ func (h *Header) loadFromBlockHeaderCoreProto(pb *iotextypes.BlockHeaderCore) er
 	return err
 }
 
-// SerializeCore returns byte stream for header core.
+// SerializeCore returns byte stream for header core, without the optional fields.
 func (h *Header) SerializeCore() []byte {
-	return byteutil.Must(proto.Marshal(h.BlockHeaderCoreProto()))
+	return byteutil.Must(proto.Marshal(h.hashedCoreProto()))
 }
 
 // Serialize returns the serialized byte stream of the block header
@@ -166,9 +275,19 @@ func (h *Header) Deserialize(buf []byte) error {
 }
 
 // HashHeader hashes the header
+// the hash is computed once and cached, the cache is reset when the header is reloaded or re-signed
 func (h *Header) HashHeader() hash.Hash256 {
-	s, _ := h.Serialize()
-	return hash.Hash256b(s)
+	if cached, ok := h.hashCache.Load().(hash.Hash256); ok {
+		return cached
+	}
+	s, _ := proto.Marshal(h.hashedHeaderProto())
+	digest := hash.Hash256b(s)
+	h.hashCache.Store(digest)
+	return digest
+}
+
+func (h *Header) resetHashCache() {
+	h.hashCache = atomic.Value{}
 }
 
 // HashHeaderCore hahes the header core.
@@ -207,6 +326,55 @@ func (h *Header) ProducerAddress() string {
 	return addr.String()
 }
 
+// cloneBloom copies a bloom filter, or returns it as is if it cannot be copied
+func cloneBloom(f bloom.BloomFilter) bloom.BloomFilter {
+	if f == nil {
+		return nil
+	}
+	var (
+		clone bloom.BloomFilter
+		err   error
+	)
+	if f.Size() == 2048 {
+		// the legacy filter carried by headers, see loadFromBlockHeaderCoreProto
+		clone, err = bloom.NewBloomFilterLegacy(2048, uint(f.NumHash()))
+	} else {
+		clone, err = bloom.NewBloomFilter(f.Size(), f.NumHash())
+	}
+	if err != nil || clone.FromBytes(f.Bytes()) != nil {
+		return f
+	}
+	return clone
+}
+
+// Equal returns true if the two headers have the same content, memoized values are not compared
+func (h *Header) Equal(other *Header) bool {
+	if h == nil || other == nil {
+		return h == other
+	}
+	if h.version != other.version ||
+		h.height != other.height ||
+		!h.timestamp.Equal(other.timestamp) ||
+		h.prevBlockHash != other.prevBlockHash ||
+		h.txRoot != other.txRoot ||
+		h.deltaStateDigest != other.deltaStateDigest ||
+		h.receiptRoot != other.receiptRoot ||
+		!bytes.Equal(h.blockSig, other.blockSig) ||
+		!bytes.Equal(h.extraData, other.extraData) {
+		return false
+	}
+	if (h.logsBloom == nil) != (other.logsBloom == nil) {
+		return false
+	}
+	if h.logsBloom != nil && !bytes.Equal(h.logsBloom.Bytes(), other.logsBloom.Bytes()) {
+		return false
+	}
+	if (h.pubkey == nil) != (other.pubkey == nil) {
+		return false
+	}
+	return h.pubkey == nil || bytes.Equal(h.pubkey.Bytes(), other.pubkey.Bytes())
+}
+
 // HeaderLogger returns a new logger with block header fields' value.
 func (h *Header) HeaderLogger(l *zap.Logger) *zap.Logger {
 	return l.With(zap.Uint32("version", h.version),
@@ -9,9 +9,11 @@ package block
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/iotexproject/go-pkgs/bloom"
 	"github.com/iotexproject/go-pkgs/hash"
 	"github.com/iotexproject/iotex-core/test/identityset"
 	"github.com/stretchr/testify/require"
@@ -60,6 +62,69 @@ func TestSerDesHeadrer(t *testing.T) {
 	require.NotNil(header.BlockHeaderCoreProto())
 	require.Equal("io1mflp9m6hcgm2qcghchsdqj3z3eccrnekx9p0ms", header.ProducerAddress())
 }
+func TestHeaderHashCache(t *testing.T) {
+	require := require.New(t)
+	h := getHeader()
+	ser, err := h.Serialize()
+	require.NoError(err)
+	expected := hash.Hash256b(ser)
+
+	var wg sync.WaitGroup
+	hashes := make([]hash.Hash256, 16)
+	for i := range hashes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hashes[i] = h.HashHeader()
+		}(i)
+	}
+	wg.Wait()
+	for _, v := range hashes {
+		require.Equal(expected, v)
+	}
+
+	// reloading the header resets the cached hash
+	h2 := getHeader()
+	h2.height = 3
+	ser, err = h2.Serialize()
+	require.NoError(err)
+	require.NoError(h.Deserialize(ser))
+	require.Equal(hash.Hash256b(ser), h.HashHeader())
+	require.NotEqual(expected, h.HashHeader())
+}
+
+func TestHeaderHashStream(t *testing.T) {
+	require := require.New(t)
+
+	h := getHeader()
+	streamed, err := h.HashStream()
+	require.NoError(err)
+	require.Equal(h.HashHeader(), streamed)
+
+	// optional fields, and a timestamp with nanoseconds
+	h = getHeader()
+	h.timestamp = h.timestamp.Add(123456789)
+	h.logsBloom, err = bloom.NewBloomFilterLegacy(2048, 3)
+	require.NoError(err)
+	h.logsBloom.Add([]byte("topic"))
+	h.extraData = []byte("extra")
+	streamed, err = h.HashStream()
+	require.NoError(err)
+	require.Equal(h.HashHeader(), streamed)
+
+	// the genesis header carries no producer
+	h = getHeader()
+	h.height = 0
+	h.pubkey = nil
+	streamed, err = h.HashStream()
+	require.NoError(err)
+	require.Equal(h.HashHeader(), streamed)
+
+	h.height = 1
+	_, err = h.HashStream()
+	require.Equal(ErrMissingProducer, err)
+}
+
 func getHeader() *Header {
 	ti, err := time.Parse("2006-Jan-02", "2019-Feb-03")
 	if err != nil {
@@ -0,0 +1,92 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/crypto"
+)
+
+// HeaderChainProof proves the header at height To descends from a trusted header at height From, for light clients.
+//
+// A header only commits to its parent through the prev hash, so a logarithmic subset of the headers cannot show
+// they are connected: the hash of a header is only known from the header itself, and every header between From
+// and To is needed to chain them. The proof therefore carries all of them, and would only become compact if
+// headers committed to ancestors further back.
+type HeaderChainProof struct {
+	From uint64
+	To   uint64
+	// Headers are the headers from height From+1 to To in ascending order
+	Headers []*Header
+}
+
+// BuildHeaderChainProof builds the proof that the header at height to descends from the header at height from, out
+// of headers, which must include every height in between and may be in any order
+func BuildHeaderChainProof(headers []*Header, from, to uint64) (*HeaderChainProof, error) {
+	if from >= to {
+		return nil, errors.Errorf("invalid height range [%d, %d]", from, to)
+	}
+	byHeight := make(map[uint64]*Header, len(headers))
+	for _, h := range headers {
+		if h != nil {
+			byHeight[h.Height()] = h
+		}
+	}
+	proof := &HeaderChainProof{
+		From:    from,
+		To:      to,
+		Headers: make([]*Header, 0, to-from),
+	}
+	for height := from + 1; height <= to; height++ {
+		h, ok := byHeight[height]
+		if !ok {
+			return nil, errors.Errorf("missing header at height %d", height)
+		}
+		proof.Headers = append(proof.Headers, h)
+	}
+	if base, ok := byHeight[from]; ok {
+		if err := verifyHeaderChain(base.Height(), base.HashBlock(), proof.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// VerifyHeaderChainProof verifies the headers of the proof chain from trusted, which is the header at height From,
+// to the header at height To, by height and by prev hash
+func VerifyHeaderChainProof(trusted *Header, proof *HeaderChainProof) error {
+	if trusted == nil || proof == nil {
+		return errors.New("missing trusted header or proof")
+	}
+	if trusted.Height() != proof.From {
+		return errors.Wrapf(ErrParentHeightMismatch, "trusted height %d, proof from %d", trusted.Height(), proof.From)
+	}
+	if proof.To <= proof.From || uint64(len(proof.Headers)) != proof.To-proof.From {
+		return errors.Errorf("proof from %d to %d has %d headers", proof.From, proof.To, len(proof.Headers))
+	}
+	return verifyHeaderChain(trusted.Height(), trusted.HashBlock(), proof.Headers)
+}
+
+// RangeStateCommitment returns the merkle root over the delta state digests of headers, in order, with the same
+// scheme as the tx root. The headers must be a contiguous range in ascending height order, each following the
+// previous one by height and by prev hash.
+func RangeStateCommitment(headers []*Header) (hash.Hash256, error) {
+	if len(headers) == 0 || headers[0] == nil {
+		return hash.ZeroHash256, errors.New("empty header range")
+	}
+	first := headers[0]
+	if err := verifyHeaderChain(first.Height(), first.HashBlock(), headers[1:]); err != nil {
+		return hash.ZeroHash256, err
+	}
+	digests := make([]hash.Hash256, len(headers))
+	for i, h := range headers {
+		digests[i] = h.DeltaStateDigest()
+	}
+	return crypto.NewMerkleTree(digests).HashTree(), nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/test/identityset"
+	"github.com/iotexproject/iotex-core/testutil"
+)
+
+func TestHeaderChainProof(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		headers []*Header
+		prev    = hash.Hash256b([]byte("0"))
+	)
+	for height := uint64(1); height <= 20; height++ {
+		blk, err := NewTestingBuilder().
+			SetHeight(height).
+			SetPrevBlockHash(prev).
+			SetTimeStamp(testutil.TimestampNow()).
+			SignAndBuild(identityset.PrivateKey(int(height % 5)))
+		require.NoError(err)
+		headers = append(headers, &blk.Header)
+		prev = blk.HashBlock()
+	}
+	// headers in any order
+	shuffled := append([]*Header{}, headers...)
+	shuffled[0], shuffled[19] = shuffled[19], shuffled[0]
+
+	proof, err := BuildHeaderChainProof(shuffled, 3, 17)
+	require.NoError(err)
+	require.Len(proof.Headers, 14)
+	require.NoError(VerifyHeaderChainProof(headers[2], proof))
+	// the proof only holds from the trusted header
+	require.Equal(ErrParentHeightMismatch, errors.Cause(VerifyHeaderChainProof(headers[3], proof)))
+	require.Error(VerifyHeaderChainProof(nil, proof))
+
+	// a tampered header breaks the chain
+	tampered := *proof
+	tampered.Headers = append([]*Header{}, proof.Headers...)
+	forged, err := NewTestingBuilder().
+		SetHeight(10).
+		SetPrevBlockHash(headers[8].HashBlock()).
+		SetTimeStamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(9))
+	require.NoError(err)
+	tampered.Headers[6] = &forged.Header
+	require.Equal(ErrPrevHashMismatch, errors.Cause(VerifyHeaderChainProof(headers[2], &tampered)))
+	// so does a missing one
+	tampered.Headers = append(append([]*Header{}, proof.Headers[:6]...), proof.Headers[7:]...)
+	require.Error(VerifyHeaderChainProof(headers[2], &tampered))
+
+	// the headers must cover the range and chain
+	_, err = BuildHeaderChainProof(headers[5:], 3, 17)
+	require.Error(err)
+	_, err = BuildHeaderChainProof(headers, 17, 17)
+	require.Error(err)
+	_, err = BuildHeaderChainProof(append(append([]*Header{&forged.Header}, headers[:9]...), headers[10]), 3, 11)
+	require.Equal(ErrPrevHashMismatch, errors.Cause(err))
+}
+
+func TestRangeStateCommitment(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		headers []*Header
+		prev    = hash.Hash256b([]byte("0"))
+	)
+	for height := uint64(1); height <= 6; height++ {
+		blk, err := NewBuilder(NewRunnableActionsBuilder().Build()).
+			SetHeight(height).
+			SetPrevBlockHash(prev).
+			SetTimestamp(testutil.TimestampNow()).
+			SetDeltaStateDigest(hash.Hash256b([]byte{byte(height)})).
+			SignAndBuild(identityset.PrivateKey(0))
+		require.NoError(err)
+		headers = append(headers, &blk.Header)
+		prev = blk.HashBlock()
+	}
+
+	root, err := RangeStateCommitment(headers)
+	require.NoError(err)
+	digests := make([]hash.Hash256, len(headers))
+	for i, h := range headers {
+		digests[i] = h.DeltaStateDigest()
+	}
+	require.Equal(crypto.NewMerkleTree(digests).HashTree(), root)
+	again, err := RangeStateCommitment(headers)
+	require.NoError(err)
+	require.Equal(root, again)
+	sub, err := RangeStateCommitment(headers[1:4])
+	require.NoError(err)
+	require.NotEqual(root, sub)
+	single, err := RangeStateCommitment(headers[5:])
+	require.NoError(err)
+	require.Equal(headers[5].DeltaStateDigest(), single)
+
+	// a missing height, out of order headers, or no header
+	_, err = RangeStateCommitment(append(append([]*Header{}, headers[:2]...), headers[3:]...))
+	require.Equal(ErrParentHeightMismatch, errors.Cause(err))
+	_, err = RangeStateCommitment([]*Header{headers[1], headers[0]})
+	require.Equal(ErrParentHeightMismatch, errors.Cause(err))
+	_, err = RangeStateCommitment(nil)
+	require.Error(err)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// HashStream returns the same hash as HashHeader, but writes the fields of the header into the hasher one by one
+// in their protobuf encoding, rather than serializing the whole header into a buffer first. It neither reads nor
+// fills the hash cache, so every call hashes the header again.
+func (h *Header) HashStream() (hash.Hash256, error) {
+	var pubkey []byte
+	if h.height > 0 {
+		if h.pubkey == nil {
+			return hash.ZeroHash256, ErrMissingProducer
+		}
+		pubkey = h.pubkey.Bytes()
+	}
+	hasher := sha3.NewLegacyKeccak256()
+	w := &protoStreamWriter{w: hasher}
+	// fields of iotextypes.BlockHeader
+	w.message(1, h.writeCore)
+	w.bytes(2, pubkey)
+	w.bytes(3, h.blockSig)
+	if w.err != nil {
+		return hash.ZeroHash256, w.err
+	}
+	var digest hash.Hash256
+	copy(digest[:], hasher.Sum(nil))
+	return digest, nil
+}
+
+// writeCore writes the known fields of iotextypes.BlockHeaderCore by field number, like proto.Marshal does. The
+// optional extension fields are not part of the hash.
+func (h *Header) writeCore(w *protoStreamWriter) {
+	w.varint(1, uint64(h.version))
+	w.varint(2, h.height)
+	w.message(3, func(w *protoStreamWriter) {
+		w.varint(1, uint64(h.timestamp.Unix()))
+		w.varint(2, uint64(int64(h.timestamp.Nanosecond())))
+	})
+	w.bytes(4, h.prevBlockHash[:])
+	w.bytes(5, h.txRoot[:])
+	w.bytes(6, h.deltaStateDigest[:])
+	w.bytes(7, h.receiptRoot[:])
+	if h.logsBloom != nil {
+		w.bytes(8, h.logsBloom.Bytes())
+	}
+}
+
+// protoStreamWriter writes proto3 fields to w, omitting zero values like proto.Marshal. The first error is
+// kept and the following writes are skipped.
+type protoStreamWriter struct {
+	w       io.Writer
+	n       int
+	err     error
+	scratch [2 * binary.MaxVarintLen64]byte
+}
+
+func (s *protoStreamWriter) write(p []byte) {
+	if s.err != nil {
+		return
+	}
+	n, err := s.w.Write(p)
+	s.n += n
+	s.err = err
+}
+
+func (s *protoStreamWriter) varint(num protowire.Number, v uint64) {
+	if v == 0 {
+		return
+	}
+	b := protowire.AppendTag(s.scratch[:0], num, protowire.VarintType)
+	s.write(protowire.AppendVarint(b, v))
+}
+
+func (s *protoStreamWriter) bytes(num protowire.Number, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	s.header(num, len(v))
+	s.write(v)
+}
+
+// message writes a nested message, which is always written even if empty, its size is counted by a first
+// pass of writeFields into io.Discard
+func (s *protoStreamWriter) message(num protowire.Number, writeFields func(*protoStreamWriter)) {
+	size := &protoStreamWriter{w: io.Discard}
+	writeFields(size)
+	s.header(num, size.n)
+	writeFields(s)
+}
+
+func (s *protoStreamWriter) header(num protowire.Number, size int) {
+	b := protowire.AppendTag(s.scratch[:0], num, protowire.BytesType)
+	s.write(protowire.AppendVarint(b, uint64(size)))
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/action"
+)
+
+// ErrActionNotInBlock indicates the block has no receipt for the action
+var ErrActionNotInBlock = errors.New("action not found in block")
+
+// LogProof is the merkle proof that a log was emitted by an action, against the receipt root of a block. The
+// proof carries the serialized receipt of the action, whose hash is a leaf of the receipt root, so the log is
+// tied to the action by the receipt and the receipt to the root by the siblings.
+type LogProof struct {
+	ActionHash hash.Hash256
+	// LogIndex is the position of the log in the receipt
+	LogIndex uint32
+	// Receipt is the serialized receipt of the action
+	Receipt []byte
+	// ReceiptIndex is the position of the receipt in the block
+	ReceiptIndex uint32
+	// Siblings are the sibling nodes on the path from the receipt to the root, from the bottom up
+	Siblings []hash.Hash256
+}
+
+// LogInclusionProof returns the proof of the logIndex-th log emitted by the action. The receipt root is computed
+// over the receipts attached to the block, in the same order.
+func (b *Block) LogInclusionProof(actionHash hash.Hash256, logIndex uint32) (*LogProof, error) {
+	if b.Receipts == nil && len(b.Actions) > 0 {
+		return nil, ErrNoReceipts
+	}
+	r, ok := b.ReceiptForAction(actionHash)
+	if !ok {
+		return nil, errors.Wrapf(ErrActionNotInBlock, "action %x", actionHash)
+	}
+	if int(logIndex) >= len(r.Logs()) {
+		return nil, errors.Errorf("log index %d out of range [0, %d)", logIndex, len(r.Logs()))
+	}
+	var (
+		leaves = make([]hash.Hash256, len(b.Receipts))
+		pos    = -1
+	)
+	for i, receipt := range b.Receipts {
+		if receipt == nil {
+			return nil, errors.Wrapf(ErrReceiptMismatch, "receipt %d is nil", i)
+		}
+		if receipt == r && pos < 0 {
+			pos = i
+		}
+		leaves[i] = receipt.Hash()
+	}
+	ser, err := r.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &LogProof{
+		ActionHash:   actionHash,
+		LogIndex:     logIndex,
+		Receipt:      ser,
+		ReceiptIndex: uint32(pos),
+		Siblings:     merkleProof(leaves, pos),
+	}, nil
+}
+
+// Log returns the log proven by the proof, it is only trustworthy after VerifyLogProof succeeds
+func (p *LogProof) Log() (*action.Log, error) {
+	r := &action.Receipt{}
+	if err := r.Deserialize(p.Receipt); err != nil {
+		return nil, err
+	}
+	if int(p.LogIndex) >= len(r.Logs()) {
+		return nil, errors.Errorf("log index %d out of range [0, %d)", p.LogIndex, len(r.Logs()))
+	}
+	return r.Logs()[p.LogIndex], nil
+}
+
+// VerifyLogProof returns true if the proof shows the receipt of the action, which has a log at LogIndex, is
+// included in receiptRoot
+func VerifyLogProof(receiptRoot hash.Hash256, proof *LogProof) bool {
+	if proof == nil || receiptRoot == hash.ZeroHash256 {
+		return false
+	}
+	r := &action.Receipt{}
+	if err := r.Deserialize(proof.Receipt); err != nil {
+		return false
+	}
+	if r.ActionHash != proof.ActionHash || int(proof.LogIndex) >= len(r.Logs()) {
+		return false
+	}
+	leaf := hash.Hash256b(proof.Receipt)
+	return merkleProofRoot(leaf, proof.ReceiptIndex, proof.Siblings, hashPair) == receiptRoot
+}
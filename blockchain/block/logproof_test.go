@@ -0,0 +1,69 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/crypto"
+)
+
+func TestLogInclusionProof(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 5)
+	h, err := blk.Actions[2].Hash()
+	require.NoError(err)
+	_, err = blk.LogInclusionProof(h, 0)
+	require.Equal(ErrNoReceipts, errors.Cause(err))
+
+	receipts := makeReceipts(t, blk, 2)
+	require.NoError(blk.SetReceipts(receipts))
+	leaves := make([]hash.Hash256, len(receipts))
+	for i, r := range receipts {
+		leaves[i] = r.Hash()
+	}
+	root := crypto.NewMerkleTree(leaves).HashTree()
+
+	for i := range blk.Actions {
+		h, err := blk.Actions[i].Hash()
+		require.NoError(err)
+		for j := uint32(0); j < 2; j++ {
+			proof, err := blk.LogInclusionProof(h, j)
+			require.NoError(err)
+			require.Equal(uint32(i), proof.ReceiptIndex)
+			require.True(VerifyLogProof(root, proof))
+			l, err := proof.Log()
+			require.NoError(err)
+			require.Equal(receipts[i].Logs()[j].Topics, l.Topics)
+			require.Equal(receipts[i].Logs()[j].Address, l.Address)
+
+			// the proof does not hold against another root, for another action or with a tampered receipt
+			require.False(VerifyLogProof(hash.Hash256b([]byte("root")), proof))
+			other := *proof
+			other.ActionHash = hash.Hash256b([]byte("action"))
+			require.False(VerifyLogProof(root, &other))
+			other = *proof
+			other.Receipt = append([]byte{}, proof.Receipt...)
+			other.Receipt[len(other.Receipt)-1] ^= 1
+			require.False(VerifyLogProof(root, &other))
+			other = *proof
+			other.LogIndex = 2
+			require.False(VerifyLogProof(root, &other))
+		}
+	}
+	require.False(VerifyLogProof(root, nil))
+
+	_, err = blk.LogInclusionProof(h, 2)
+	require.Error(err)
+	_, err = blk.LogInclusionProof(hash.Hash256b([]byte("action")), 0)
+	require.Equal(ErrActionNotInBlock, errors.Cause(err))
+}
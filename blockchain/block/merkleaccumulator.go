@@ -0,0 +1,114 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/iotexproject/go-pkgs/hash"
+
+	"github.com/iotexproject/iotex-core/action"
+)
+
+// MerkleAccumulator computes the tx root of a growing list of action hashes. Add takes amortized
+// constant time and Root takes logarithmic time, the result is the same as CalculateTxRoot over the
+// same ordered leaves, including the duplication of the last node on levels of odd size.
+type MerkleAccumulator struct {
+	// pending[i] is the root of a complete subtree of 2^i leaves waiting for its right sibling
+	pending []*hash.Hash256
+	size    int
+}
+
+// NewMerkleAccumulator creates an empty accumulator
+func NewMerkleAccumulator() *MerkleAccumulator {
+	return &MerkleAccumulator{}
+}
+
+// Add appends a leaf
+func (acc *MerkleAccumulator) Add(leaf hash.Hash256) {
+	node := leaf
+	for i := 0; ; i++ {
+		if i == len(acc.pending) {
+			acc.pending = append(acc.pending, nil)
+		}
+		if acc.pending[i] == nil {
+			acc.pending[i] = &node
+			break
+		}
+		node = hashPair(*acc.pending[i], node)
+		acc.pending[i] = nil
+	}
+	acc.size++
+}
+
+// Len returns the number of leaves added
+func (acc *MerkleAccumulator) Len() int {
+	return acc.size
+}
+
+// Root returns the merkle root of the leaves added so far, or hash.ZeroHash256 if there is none
+func (acc *MerkleAccumulator) Root() hash.Hash256 {
+	if acc.size == 0 {
+		return hash.ZeroHash256
+	}
+	// carry is the root of the incomplete subtree at the end of the current level
+	var (
+		carry *hash.Hash256
+		level int
+	)
+	for size := acc.size; size > 1; size = (size + 1) >> 1 {
+		var node hash.Hash256
+		switch left := acc.pending[level]; {
+		case left != nil && carry != nil:
+			node = hashPair(*left, *carry)
+		case left != nil:
+			node = hashPair(*left, *left)
+		case carry != nil:
+			node = hashPair(*carry, *carry)
+		default:
+			level++
+			continue
+		}
+		carry = &node
+		level++
+	}
+	if carry != nil {
+		return *carry
+	}
+	return *acc.pending[level]
+}
+
+// ReceiptAccumulator computes the receipt root of a growing list of receipts, the result is the same as the
+// receipt root the state factory computes over the same ordered receipts, which VerifyReceiptRoot checks
+type ReceiptAccumulator struct {
+	acc MerkleAccumulator
+}
+
+// NewReceiptAccumulator creates an empty accumulator
+func NewReceiptAccumulator() *ReceiptAccumulator {
+	return &ReceiptAccumulator{}
+}
+
+// Add appends a receipt, the receipt must not be modified afterwards
+func (acc *ReceiptAccumulator) Add(receipt *action.Receipt) {
+	acc.acc.Add(receipt.Hash())
+}
+
+// Len returns the number of receipts added
+func (acc *ReceiptAccumulator) Len() int {
+	return acc.acc.Len()
+}
+
+// Root returns the receipt root of the receipts added so far, or hash.ZeroHash256 if there is none
+func (acc *ReceiptAccumulator) Root() hash.Hash256 {
+	return acc.acc.Root()
+}
+
+func hashPair(left, right hash.Hash256) hash.Hash256 {
+	h := make([]byte, 0, 2*len(left))
+	h = append(h, left[:]...)
+	h = append(h, right[:]...)
+	return hash.Hash256b(h)
+}
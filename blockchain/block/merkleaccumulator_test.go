@@ -0,0 +1,149 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/crypto"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func makeLeaves(n int) []hash.Hash256 {
+	leaves := make([]hash.Hash256, n)
+	for i := range leaves {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		leaves[i] = hash.Hash256b(b[:])
+	}
+	return leaves
+}
+
+func TestMerkleAccumulator(t *testing.T) {
+	require := require.New(t)
+
+	acc := NewMerkleAccumulator()
+	require.Zero(acc.Len())
+	require.Equal(hash.ZeroHash256, acc.Root())
+
+	leaves := makeLeaves(130)
+	for i, leaf := range leaves {
+		acc.Add(leaf)
+		require.Equal(i+1, acc.Len())
+		require.Equal(crypto.NewMerkleTree(leaves[:i+1]).HashTree(), acc.Root(), "%d leaves", i+1)
+	}
+
+	// matches the tx root of a block
+	blk := makeBlock(t, 11)
+	acc = NewMerkleAccumulator()
+	for _, selp := range blk.Actions {
+		h, err := selp.Hash()
+		require.NoError(err)
+		acc.Add(h)
+	}
+	txRoot, err := blk.CalculateTxRoot()
+	require.NoError(err)
+	require.Equal(txRoot, acc.Root())
+	require.Equal(blk.TxRoot(), acc.Root())
+}
+
+func BenchmarkMerkleAccumulator(b *testing.B) {
+	leaves := makeLeaves(2000)
+	b.Run("accumulator", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			acc := NewMerkleAccumulator()
+			for _, leaf := range leaves {
+				acc.Add(leaf)
+				_ = acc.Root()
+			}
+		}
+	})
+	b.Run("full recompute", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for i := range leaves {
+				_ = crypto.NewMerkleTree(leaves[:i+1]).HashTreeWith(hash.Hash256b)
+			}
+		}
+	})
+}
+
+func makeAccumulatorReceipts(tb testing.TB, n int) []*action.Receipt {
+	blk := makeBlock(tb, n)
+	receipts := makeReceipts(tb, blk, 0)
+	for i, r := range receipts {
+		// receipts emit a different number of logs
+		for j := 0; j < i%4; j++ {
+			r.AddLogs(&action.Log{
+				Address:     "io1",
+				Data:        []byte{byte(i), byte(j)},
+				BlockHeight: r.BlockHeight,
+				ActionHash:  r.ActionHash,
+				Index:       uint32(j),
+			})
+		}
+	}
+	return receipts
+}
+
+func TestReceiptAccumulator(t *testing.T) {
+	require := require.New(t)
+
+	acc := NewReceiptAccumulator()
+	require.Zero(acc.Len())
+	require.Equal(hash.ZeroHash256, acc.Root())
+
+	receipts := makeAccumulatorReceipts(t, 20)
+	var leaves []hash.Hash256
+	for i, r := range receipts {
+		acc.Add(r)
+		leaves = append(leaves, r.Hash())
+		require.Equal(i+1, acc.Len())
+		require.Equal(crypto.NewMerkleTree(leaves).HashTree(), acc.Root(), "%d receipts", i+1)
+	}
+
+	// the root is set in the header and verified
+	blk, err := NewBuilder(NewRunnableActionsBuilder().Build()).
+		SetHeight(1).
+		SetReceiptRoot(acc.Root()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.True(blk.VerifyReceiptRoot(crypto.NewMerkleTree(leaves).HashTree()))
+
+	// the root depends on the order of receipts
+	acc = NewReceiptAccumulator()
+	for i := len(receipts) - 1; i >= 0; i-- {
+		acc.Add(receipts[i])
+	}
+	require.NotEqual(crypto.NewMerkleTree(leaves).HashTree(), acc.Root())
+}
+
+func BenchmarkReceiptAccumulator(b *testing.B) {
+	receipts := makeAccumulatorReceipts(b, 2000)
+	b.Run("accumulator", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			acc := NewReceiptAccumulator()
+			for _, r := range receipts {
+				acc.Add(r)
+				_ = acc.Root()
+			}
+		}
+	})
+	b.Run("full recompute", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			leaves := make([]hash.Hash256, 0, len(receipts))
+			for _, r := range receipts {
+				leaves = append(leaves, r.Hash())
+				_ = crypto.NewMerkleTree(leaves).HashTree()
+			}
+		}
+	})
+}
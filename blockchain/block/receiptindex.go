@@ -0,0 +1,183 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-proto/golang/iotextypes"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/action"
+)
+
+// logIndexVersion1 is the format of MarshalLogIndex: the version byte, the uvarint number of actions,
+// then for each action sorted by hash, the hash followed by the uvarint tx index and log index
+const logIndexVersion1 byte = 1
+
+// ErrInvalidLogIndex indicates the log index data cannot be decoded
+var ErrInvalidLogIndex = errors.New("invalid log index")
+
+// MarshalLogIndex encodes the maps returned by TxLogIndexMap into a compact and deterministic byte stream,
+// which can be decoded by UnmarshalLogIndex
+func (b *Block) MarshalLogIndex() ([]byte, error) {
+	txIndex, logIndex := b.TxLogIndexMap()
+	hashes := make([]hash.Hash256, 0, len(txIndex))
+	for h := range txIndex {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	var (
+		buf = make([]byte, 0, 1+binary.MaxVarintLen64+len(hashes)*(len(hash.ZeroHash256)+4))
+		tmp [binary.MaxVarintLen64]byte
+	)
+	buf = append(buf, logIndexVersion1)
+	buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(len(hashes)))]...)
+	for _, h := range hashes {
+		buf = append(buf, h[:]...)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(txIndex[h]))]...)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], uint64(logIndex[h]))]...)
+	}
+	return buf, nil
+}
+
+// UnmarshalLogIndex decodes the byte stream produced by MarshalLogIndex into the maps returned by TxLogIndexMap
+func UnmarshalLogIndex(data []byte) (map[hash.Hash256]uint32, map[hash.Hash256]uint32, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.Wrap(ErrInvalidLogIndex, "empty data")
+	}
+	if data[0] != logIndexVersion1 {
+		return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "unsupported version %d", data[0])
+	}
+	r := bytes.NewReader(data[1:])
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "failed to read size: %v", err)
+	}
+	// each entry takes at least a hash and two bytes
+	if n > uint64(r.Len())/uint64(len(hash.ZeroHash256)+2) {
+		return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "size %d exceeds data length", n)
+	}
+	txIndex := make(map[hash.Hash256]uint32, n)
+	logIndex := make(map[hash.Hash256]uint32, n)
+	for i := uint64(0); i < n; i++ {
+		var h hash.Hash256
+		if _, err := io.ReadFull(r, h[:]); err != nil {
+			return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "failed to read hash %d: %v", i, err)
+		}
+		if _, ok := txIndex[h]; ok {
+			return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "duplicate hash %x", h)
+		}
+		for _, m := range []map[hash.Hash256]uint32{txIndex, logIndex} {
+			v, err := binary.ReadUvarint(r)
+			if err != nil || v > math.MaxUint32 {
+				return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "invalid index of hash %x", h)
+			}
+			m[h] = uint32(v)
+		}
+	}
+	if r.Len() != 0 {
+		return nil, nil, errors.Wrapf(ErrInvalidLogIndex, "%d trailing bytes", r.Len())
+	}
+	return txIndex, logIndex, nil
+}
+
+// receiptsVersion1 is the format of SerializeReceipts: the version byte, then the deterministic protobuf
+// encoding of iotextypes.Receipts
+const receiptsVersion1 byte = 1
+
+// ErrInvalidReceipts indicates the receipts data cannot be decoded
+var ErrInvalidReceipts = errors.New("invalid receipts")
+
+// SerializeReceipts encodes the receipts of the block with their logs, for a receipt store kept apart from the
+// blocks, which DeserializeReceipts decodes. The transaction logs are not included, like in Store.
+func (b *Block) SerializeReceipts() ([]byte, error) {
+	pb := &iotextypes.Receipts{
+		Receipts: make([]*iotextypes.Receipt, 0, len(b.Receipts)),
+	}
+	for i, r := range b.Receipts {
+		if r == nil {
+			return nil, errors.Wrapf(ErrInvalidReceipts, "receipt %d is nil", i)
+		}
+		pb.Receipts = append(pb.Receipts, r.ConvertToReceiptPb())
+	}
+	ser, err := proto.MarshalOptions{Deterministic: true}.Marshal(pb)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{receiptsVersion1}, ser...), nil
+}
+
+// DeserializeReceipts decodes the byte stream produced by SerializeReceipts
+func DeserializeReceipts(data []byte) ([]*action.Receipt, error) {
+	if len(data) == 0 {
+		return nil, errors.Wrap(ErrInvalidReceipts, "empty data")
+	}
+	if data[0] != receiptsVersion1 {
+		return nil, errors.Wrapf(ErrInvalidReceipts, "unsupported version %d", data[0])
+	}
+	pb := &iotextypes.Receipts{}
+	if err := proto.Unmarshal(data[1:], pb); err != nil {
+		return nil, errors.Wrapf(ErrInvalidReceipts, "failed to unmarshal: %v", err)
+	}
+	receipts := make([]*action.Receipt, 0, len(pb.Receipts))
+	for _, receiptPb := range pb.Receipts {
+		receipt := &action.Receipt{}
+		receipt.ConvertFromReceiptPb(receiptPb)
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// receiptIndex indexes the receipts of a block by action hash
+type receiptIndex struct {
+	receipts []*action.Receipt
+	byAction map[hash.Hash256]*action.Receipt
+	txIndex  map[hash.Hash256]uint32
+	logIndex map[hash.Hash256]uint32
+}
+
+// newReceiptIndex builds the index over the receipts in block order. If several receipts carry
+// the same action hash, the first one wins, but the logs of every receipt still count towards
+// the log index of the receipts after it.
+func newReceiptIndex(receipts []*action.Receipt) *receiptIndex {
+	idx := &receiptIndex{
+		receipts: receipts,
+		byAction: make(map[hash.Hash256]*action.Receipt, len(receipts)),
+		txIndex:  make(map[hash.Hash256]uint32, len(receipts)),
+		logIndex: make(map[hash.Hash256]uint32, len(receipts)),
+	}
+	var logIndex uint32
+	for i, r := range receipts {
+		if r == nil {
+			continue
+		}
+		if _, ok := idx.byAction[r.ActionHash]; !ok {
+			idx.byAction[r.ActionHash] = r
+			idx.txIndex[r.ActionHash] = uint32(i)
+			idx.logIndex[r.ActionHash] = logIndex
+		}
+		logIndex += uint32(len(r.Logs()))
+	}
+	return idx
+}
+
+// builtFrom returns true if the index was built from the given receipts slice
+func (idx *receiptIndex) builtFrom(receipts []*action.Receipt) bool {
+	if len(idx.receipts) != len(receipts) {
+		return false
+	}
+	return len(receipts) == 0 || &idx.receipts[0] == &receipts[0]
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrNotAncestor indicates a header does not descend from the given common ancestor
+var ErrNotAncestor = errors.New("header does not descend from the ancestor")
+
+// ReorgDepth returns the number of blocks of the current chain rolled back to switch to the fork, given the tip
+// of each chain and their common ancestor. Only the heights can be checked in general: both tips must be at or
+// above the ancestor, a tip at the height of the ancestor must be the ancestor, and a tip right above it must
+// have the ancestor as previous block. Proving the ancestry of deeper tips needs the headers in between.
+func ReorgDepth(current, fork, ancestor *Header) (uint64, error) {
+	if current == nil || fork == nil || ancestor == nil {
+		return 0, errors.New("missing header")
+	}
+	for _, tip := range []struct {
+		name   string
+		header *Header
+	}{
+		{"current", current},
+		{"fork", fork},
+	} {
+		if err := verifyDescendant(tip.header, ancestor); err != nil {
+			return 0, errors.Wrapf(err, "%s tip", tip.name)
+		}
+	}
+	return current.Height() - ancestor.Height(), nil
+}
+
+func verifyDescendant(h, ancestor *Header) error {
+	switch {
+	case h.Height() < ancestor.Height():
+		return errors.Wrapf(ErrNotAncestor, "height %d is below ancestor height %d", h.Height(), ancestor.Height())
+	case h.Height() == ancestor.Height() && h.HashHeader() != ancestor.HashHeader():
+		return errors.Wrapf(ErrNotAncestor, "hash %x at ancestor height %d", h.HashHeader(), h.Height())
+	case h.Height() == ancestor.Height()+1 && h.PrevHash() != ancestor.HashHeader():
+		return errors.Wrapf(ErrNotAncestor, "prev hash %x of height %d", h.PrevHash(), h.Height())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/test/identityset"
+	"github.com/iotexproject/iotex-core/testutil"
+)
+
+func TestReorgDepth(t *testing.T) {
+	require := require.New(t)
+
+	header := func(height uint64, prev hash.Hash256, producer int) *Header {
+		blk, err := NewTestingBuilder().
+			SetHeight(height).
+			SetPrevBlockHash(prev).
+			SetTimeStamp(testutil.TimestampNow()).
+			SignAndBuild(identityset.PrivateKey(producer))
+		require.NoError(err)
+		return &blk.Header
+	}
+	// chain builds count headers on top of parent, produced by producer
+	chain := func(parent *Header, count, producer int) []*Header {
+		headers := []*Header{parent}
+		for i := 0; i < count; i++ {
+			tip := headers[len(headers)-1]
+			headers = append(headers, header(tip.Height()+1, tip.HashHeader(), producer))
+		}
+		return headers[1:]
+	}
+	ancestor := header(10, hash.Hash256b([]byte("9")), 0)
+	current := chain(ancestor, 20, 1)
+	fork := chain(ancestor, 25, 2)
+
+	// shallow reorg
+	depth, err := ReorgDepth(current[1], fork[2], ancestor)
+	require.NoError(err)
+	require.EqualValues(2, depth)
+	depth, err = ReorgDepth(current[0], fork[0], ancestor)
+	require.NoError(err)
+	require.EqualValues(1, depth)
+	// deep reorg
+	depth, err = ReorgDepth(current[19], fork[24], ancestor)
+	require.NoError(err)
+	require.EqualValues(20, depth)
+	// extending the current chain rolls nothing back
+	depth, err = ReorgDepth(ancestor, fork[3], ancestor)
+	require.NoError(err)
+	require.Zero(depth)
+
+	// the ancestor must not be above either tip
+	_, err = ReorgDepth(current[3], fork[3], current[5])
+	require.Equal(ErrNotAncestor, errors.Cause(err))
+	_, err = ReorgDepth(current[5], fork[3], current[4])
+	require.Equal(ErrNotAncestor, errors.Cause(err))
+	// a tip at or right above the ancestor height must link to it
+	_, err = ReorgDepth(current[5], fork[0], current[0])
+	require.Equal(ErrNotAncestor, errors.Cause(err))
+	_, err = ReorgDepth(current[5], fork[1], current[0])
+	require.Equal(ErrNotAncestor, errors.Cause(err))
+	_, err = ReorgDepth(nil, fork[1], ancestor)
+	require.Error(err)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"math/big"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/iotexproject/iotex-address/address"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/action/protocol/rewarding/rewardingpb"
+)
+
+// RewardEntry is a reward paid to Recipient by an action of the block
+type RewardEntry struct {
+	ActionHash hash.Hash256
+	// Claimed is true for a claim from the rewarding fund, and false for a reward granted to the account of
+	// the recipient, whose kind is given by Type
+	Claimed   bool
+	Type      rewardingpb.RewardLog_RewardType
+	Recipient string
+	Amount    *big.Int
+}
+
+// RewardRecipients returns the rewards paid by the grant and claim reward actions of the block, in the order
+// of the actions. A claim pays the claimed amount to the sender of the action. The recipients and amounts of a
+// grant are decided by the rewarding protocol when the action runs, they are parsed from the reward logs in the
+// receipt of the action, so ErrNoReceipts is returned if the block has a grant action but no receipts.
+func (b *Block) RewardRecipients() ([]RewardEntry, error) {
+	entries := []RewardEntry{}
+	for i := range b.Actions {
+		selp := &b.Actions[i]
+		if selp.Envelope == nil {
+			return nil, errors.Errorf("action %d has no envelope", i)
+		}
+		switch act := selp.Action().(type) {
+		case *action.ClaimFromRewardingFund:
+			pk := selp.SrcPubkey()
+			if pk == nil {
+				return nil, errors.Wrapf(ErrMissingSignature, "action %d has no sender", i)
+			}
+			h, err := selp.Hash()
+			if err != nil {
+				return nil, err
+			}
+			amount := big.NewInt(0)
+			if act.Amount() != nil {
+				amount.Set(act.Amount())
+			}
+			entries = append(entries, RewardEntry{
+				ActionHash: h,
+				Claimed:    true,
+				Recipient:  pk.Address().String(),
+				Amount:     amount,
+			})
+		case *action.GrantReward:
+			if b.Receipts == nil {
+				return nil, ErrNoReceipts
+			}
+			h, err := selp.Hash()
+			if err != nil {
+				return nil, err
+			}
+			r, ok := b.ReceiptForAction(h)
+			if !ok {
+				return nil, errors.Wrapf(ErrReceiptMismatch, "no receipt for grant action %d", i)
+			}
+			granted, err := grantedRewards(h, r)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid receipt of grant action %d", i)
+			}
+			entries = append(entries, granted...)
+		}
+	}
+	return entries, nil
+}
+
+// grantedRewards parses the reward logs emitted by the rewarding protocol in the receipt of a grant action
+func grantedRewards(h hash.Hash256, r *action.Receipt) ([]RewardEntry, error) {
+	var entries []RewardEntry
+	for _, l := range r.Logs() {
+		if l.Address != address.RewardingProtocol {
+			continue
+		}
+		rewardLog := &rewardingpb.RewardLog{}
+		if err := proto.Unmarshal(l.Data, rewardLog); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal reward log")
+		}
+		amount, ok := new(big.Int).SetString(rewardLog.Amount, 10)
+		if !ok {
+			return nil, errors.Errorf("invalid reward amount %s", rewardLog.Amount)
+		}
+		entries = append(entries, RewardEntry{
+			ActionHash: h,
+			Type:       rewardLog.Type,
+			Recipient:  rewardLog.Addr,
+			Amount:     amount,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iotexproject/iotex-address/address"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/action/protocol/rewarding/rewardingpb"
+	"github.com/iotexproject/iotex-core/test/identityset"
+	"github.com/iotexproject/iotex-core/testutil"
+)
+
+func TestRewardRecipients(t *testing.T) {
+	require := require.New(t)
+
+	// no reward action
+	blk := makeBlock(t, 2)
+	entries, err := blk.RewardRecipients()
+	require.NoError(err)
+	require.NotNil(entries)
+	require.Empty(entries)
+
+	grant := (&action.GrantRewardBuilder{}).SetRewardType(action.BlockReward).SetHeight(1).Build()
+	grantSelp, err := action.Sign((&action.EnvelopeBuilder{}).SetNonce(1).SetGasLimit(0).SetAction(&grant).Build(), identityset.PrivateKey(0))
+	require.NoError(err)
+	claim := (&action.ClaimFromRewardingFundBuilder{}).SetAmount(big.NewInt(300)).Build()
+	claimSelp, err := action.Sign((&action.EnvelopeBuilder{}).SetNonce(1).SetGasLimit(100000).SetGasPrice(big.NewInt(10)).SetAction(&claim).Build(), identityset.PrivateKey(2))
+	require.NoError(err)
+	rewardBlk, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(testutil.TimestampNow()).
+		AddActions(grantSelp, claimSelp).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	blk = &rewardBlk
+	_, err = blk.RewardRecipients()
+	require.Equal(ErrNoReceipts, errors.Cause(err))
+
+	receipts := makeReceipts(t, blk, 1)
+	data, err := proto.Marshal(&rewardingpb.RewardLog{
+		Type:   rewardingpb.RewardLog_BLOCK_REWARD,
+		Addr:   identityset.Address(1).String(),
+		Amount: "16000000000000000000",
+	})
+	require.NoError(err)
+	receipts[0].AddLogs(&action.Log{
+		Address: address.RewardingProtocol,
+		Data:    data,
+	})
+	require.NoError(blk.SetReceipts(receipts))
+	entries, err = blk.RewardRecipients()
+	require.NoError(err)
+	require.Len(entries, 2)
+	grantHash, err := grantSelp.Hash()
+	require.NoError(err)
+	require.Equal(grantHash, entries[0].ActionHash)
+	require.False(entries[0].Claimed)
+	require.Equal(rewardingpb.RewardLog_BLOCK_REWARD, entries[0].Type)
+	require.Equal(identityset.Address(1).String(), entries[0].Recipient)
+	require.Equal("16000000000000000000", entries[0].Amount.String())
+	require.True(entries[1].Claimed)
+	require.Equal(identityset.Address(2).String(), entries[1].Recipient)
+	require.Zero(entries[1].Amount.Cmp(big.NewInt(300)))
+
+	// a log of the rewarding protocol which is not a reward log
+	receipts[0].AddLogs(&action.Log{
+		Address: address.RewardingProtocol,
+		Data:    []byte{0xff},
+	})
+	_, err = blk.RewardRecipients()
+	require.Error(err)
+}
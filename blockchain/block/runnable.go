@@ -7,7 +7,11 @@
 package block
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/iotexproject/iotex-core/action"
@@ -28,6 +32,17 @@ func (ra RunnableActions) Actions() []action.SealedEnvelope {
 	return ra.actions
 }
 
+// Hash returns the merkle root of the ordered action hashes, computed the same way as the tx root.
+// Unlike TxHash, it is always computed from the actions. An all-0 return value means an action cannot be hashed.
+func (ra RunnableActions) Hash() hash.Hash256 {
+	h, err := calculateTxRoot(ra.actions)
+	if err != nil {
+		log.L().Debug("error in getting hash", zap.Error(err))
+		return hash.ZeroHash256
+	}
+	return h
+}
+
 // RunnableActionsBuilder is used to construct RunnableActions.
 type RunnableActionsBuilder struct{ ra RunnableActions }
 
@@ -53,3 +68,51 @@ func (b *RunnableActionsBuilder) Build() RunnableActions {
 	}
 	return b.ra
 }
+
+// SortActionsCanonical returns the actions in the canonical order proposers use to assemble a block, so that
+// proposers given the same actions build the same block. Actions are sorted by sender address, then by nonce,
+// then by gas price from the highest, and last by action hash, which makes the order total. The input slice is
+// not modified.
+func SortActionsCanonical(actions []action.SealedEnvelope) ([]action.SealedEnvelope, error) {
+	type sortKey struct {
+		sender string
+		hash   hash.Hash256
+	}
+	keys := make([]sortKey, len(actions))
+	for i := range actions {
+		if actions[i].Envelope == nil || actions[i].SrcPubkey() == nil {
+			return nil, errors.Errorf("action %d has no sender", i)
+		}
+		sender := actions[i].SrcPubkey().Address()
+		if sender == nil {
+			return nil, errors.Errorf("failed to get sender address of action %d", i)
+		}
+		h, err := actions[i].Hash()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash action %d", i)
+		}
+		keys[i] = sortKey{sender.String(), h}
+	}
+	order := make([]int, len(actions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if keys[a].sender != keys[b].sender {
+			return keys[a].sender < keys[b].sender
+		}
+		if na, nb := actions[a].Nonce(), actions[b].Nonce(); na != nb {
+			return na < nb
+		}
+		if c := actions[a].GasPrice().Cmp(actions[b].GasPrice()); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(keys[a].hash[:], keys[b].hash[:]) < 0
+	})
+	sorted := make([]action.SealedEnvelope, len(actions))
+	for i, j := range order {
+		sorted[i] = actions[j]
+	}
+	return sorted, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestRunnableActionsHash(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(hash.ZeroHash256, NewRunnableActionsBuilder().Build().Hash())
+
+	var acts []action.SealedEnvelope
+	for i := 0; i < 3; i++ {
+		selp, err := action.SignedTransfer(identityset.Address(i).String(), identityset.PrivateKey(1), uint64(i+1), big.NewInt(1), nil, 100000, big.NewInt(10))
+		require.NoError(err)
+		acts = append(acts, selp)
+	}
+	ra1 := NewRunnableActionsBuilder().AddActions(acts...).Build()
+	ra2 := NewRunnableActionsBuilder().AddActions(acts[0]).AddActions(acts[1:]...).Build()
+	require.NotEqual(hash.ZeroHash256, ra1.Hash())
+	require.Equal(ra1.TxHash(), ra1.Hash())
+	require.Equal(ra1.Hash(), ra2.Hash())
+
+	ra3 := NewRunnableActionsBuilder().AddActions(acts[1], acts[0], acts[2]).Build()
+	require.NotEqual(ra1.Hash(), ra3.Hash())
+	ra4 := NewRunnableActionsBuilder().AddActions(acts[:2]...).Build()
+	require.NotEqual(ra1.Hash(), ra4.Hash())
+}
+
+func TestSortActionsCanonical(t *testing.T) {
+	require := require.New(t)
+
+	sorted, err := SortActionsCanonical(nil)
+	require.NoError(err)
+	require.Empty(sorted)
+
+	var acts []action.SealedEnvelope
+	for i := 0; i < 4; i++ {
+		for nonce := uint64(1); nonce <= 3; nonce++ {
+			selp, err := action.SignedTransfer(identityset.Address(9).String(), identityset.PrivateKey(i), nonce, big.NewInt(1), nil, 100000, big.NewInt(10))
+			require.NoError(err)
+			acts = append(acts, selp)
+		}
+	}
+	// same sender and nonce, with a higher gas price and with the same gas price
+	higher, err := action.SignedTransfer(identityset.Address(9).String(), identityset.PrivateKey(0), 2, big.NewInt(1), nil, 100000, big.NewInt(20))
+	require.NoError(err)
+	same, err := action.SignedTransfer(identityset.Address(8).String(), identityset.PrivateKey(0), 2, big.NewInt(1), nil, 100000, big.NewInt(10))
+	require.NoError(err)
+	acts = append(acts, higher, same)
+
+	expected, err := SortActionsCanonical(acts)
+	require.NoError(err)
+	require.Len(expected, len(acts))
+	for i := 1; i < len(expected); i++ {
+		prev, cur := expected[i-1], expected[i]
+		if prev.SrcPubkey().Address().String() != cur.SrcPubkey().Address().String() {
+			require.Less(prev.SrcPubkey().Address().String(), cur.SrcPubkey().Address().String())
+			continue
+		}
+		require.LessOrEqual(prev.Nonce(), cur.Nonce())
+		if prev.Nonce() == cur.Nonce() {
+			require.GreaterOrEqual(prev.GasPrice().Cmp(cur.GasPrice()), 0)
+		}
+	}
+	expectedRoot, err := calculateTxRoot(expected)
+	require.NoError(err)
+
+	shuffled := append([]action.SealedEnvelope{}, acts...)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		input := append([]action.SealedEnvelope{}, shuffled...)
+		sorted, err := SortActionsCanonical(shuffled)
+		require.NoError(err)
+		require.Equal(input, shuffled)
+		root, err := calculateTxRoot(sorted)
+		require.NoError(err)
+		require.Equal(expectedRoot, root)
+	}
+
+	_, err = SortActionsCanonical(append(acts, action.SealedEnvelope{}))
+	require.Error(err)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/pkg/compress"
+)
+
+// MaxSegmentRecordSize is the maximum size of a compressed block in a segment file
+var MaxSegmentRecordSize uint32 = 64 << 20
+
+// ErrInvalidSegmentRecord indicates a record of a segment file cannot be read
+var ErrInvalidSegmentRecord = errors.New("invalid segment record")
+
+// segmentLengthSize is the size of the big-endian length prefix of a segment record
+const segmentLengthSize = 4
+
+// SegmentWriter appends blocks to a segment file, each block with its receipts is compressed and written
+// as a record prefixed by its length, and the offset of the record is indexed by block height
+type SegmentWriter struct {
+	w      io.Writer
+	codec  compress.Codec
+	offset int64
+	index  map[uint64]int64
+}
+
+// NewSegmentWriter creates a SegmentWriter writing to w, which is at offset in the segment file
+func NewSegmentWriter(w io.Writer, offset int64, codec compress.Codec) *SegmentWriter {
+	return &SegmentWriter{
+		w:      w,
+		codec:  codec,
+		offset: offset,
+		index:  make(map[uint64]int64),
+	}
+}
+
+// Append writes the block and returns the offset of its record
+func (sw *SegmentWriter) Append(blk *Block) (int64, error) {
+	ser, err := (&Store{Block: blk, Receipts: blk.Receipts}).Serialize()
+	if err != nil {
+		return 0, err
+	}
+	data, err := sw.codec.Compress(ser)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(data)) > uint64(MaxSegmentRecordSize) {
+		return 0, errors.Wrapf(ErrInvalidSegmentRecord, "block %d takes %d bytes", blk.Height(), len(data))
+	}
+	record := make([]byte, segmentLengthSize, segmentLengthSize+len(data))
+	binary.BigEndian.PutUint32(record, uint32(len(data)))
+	record = append(record, data...)
+	if _, err := sw.w.Write(record); err != nil {
+		return 0, errors.Wrapf(err, "failed to write block %d", blk.Height())
+	}
+	offset := sw.offset
+	sw.offset += int64(len(record))
+	sw.index[blk.Height()] = offset
+	return offset, nil
+}
+
+// Offset returns the offset of the next record
+func (sw *SegmentWriter) Offset() int64 {
+	return sw.offset
+}
+
+// FlushIndex writes the index of the blocks appended so far to w, as pairs of big-endian uint64 height and
+// offset sorted by height, which ReadSegmentIndex reads back
+func (sw *SegmentWriter) FlushIndex(w io.Writer) error {
+	heights := make([]uint64, 0, len(sw.index))
+	for h := range sw.index {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	buf := make([]byte, 16*len(heights))
+	for i, h := range heights {
+		binary.BigEndian.PutUint64(buf[16*i:], h)
+		binary.BigEndian.PutUint64(buf[16*i+8:], uint64(sw.index[h]))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadSegmentIndex reads the index written by SegmentWriter.FlushIndex
+func ReadSegmentIndex(r io.Reader) (map[uint64]int64, error) {
+	index := make(map[uint64]int64)
+	var entry [16]byte
+	for {
+		if _, err := io.ReadFull(r, entry[:]); err == io.EOF {
+			return index, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(ErrInvalidSegmentRecord, "failed to read index: %v", err)
+		}
+		offset := binary.BigEndian.Uint64(entry[8:])
+		if offset > math.MaxInt64 {
+			return nil, errors.Wrapf(ErrInvalidSegmentRecord, "invalid offset %d", offset)
+		}
+		index[binary.BigEndian.Uint64(entry[:8])] = int64(offset)
+	}
+}
+
+// SegmentReader reads blocks from a segment file written by SegmentWriter
+type SegmentReader struct {
+	r     io.ReaderAt
+	codec compress.Codec
+}
+
+// NewSegmentReader creates a SegmentReader reading from r, the codec must be the one of the writer
+func NewSegmentReader(r io.ReaderAt, codec compress.Codec) *SegmentReader {
+	return &SegmentReader{
+		r:     r,
+		codec: codec,
+	}
+}
+
+// ReadAt reads the block of the record at offset, along with its receipts
+func (sr *SegmentReader) ReadAt(offset int64) (*Block, error) {
+	var length [segmentLengthSize]byte
+	if _, err := sr.r.ReadAt(length[:], offset); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSegmentRecord, "failed to read length at %d: %v", offset, err)
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > MaxSegmentRecordSize {
+		return nil, errors.Wrapf(ErrInvalidSegmentRecord, "record at %d takes %d bytes", offset, size)
+	}
+	data := make([]byte, size)
+	if _, err := sr.r.ReadAt(data, offset+segmentLengthSize); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSegmentRecord, "failed to read record at %d: %v", offset, err)
+	}
+	ser, err := sr.codec.Decompress(data)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSegmentRecord, "failed to decompress record at %d: %v", offset, err)
+	}
+	store := &Store{}
+	if err := store.Deserialize(ser); err != nil {
+		return nil, err
+	}
+	if len(store.Receipts) > 0 {
+		store.Block.Receipts = store.Receipts
+	}
+	return store.Block, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/pkg/compress"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestSegment(t *testing.T) {
+	require := require.New(t)
+
+	for _, codec := range compress.Codecs {
+		path := filepath.Join(t.TempDir(), "segment")
+		f, err := os.Create(path)
+		require.NoError(err)
+		sw := NewSegmentWriter(f, 0, codec)
+		var (
+			blks    []*Block
+			offsets []int64
+		)
+		for i := 0; i < 5; i++ {
+			blk, err := NewTestingBuilder().
+				SetHeight(uint64(i + 1)).
+				AddActions(makeBlock(t, i+1).Actions...).
+				SignAndBuild(identityset.PrivateKey(0))
+			require.NoError(err)
+			if i%2 == 0 {
+				blk.Receipts = makeReceipts(t, &blk, i)
+			}
+			offset, err := sw.Append(&blk)
+			require.NoError(err)
+			blks = append(blks, &blk)
+			offsets = append(offsets, offset)
+		}
+		require.Zero(offsets[0])
+		require.NoError(f.Close())
+
+		var index bytes.Buffer
+		require.NoError(sw.FlushIndex(&index))
+		heights, err := ReadSegmentIndex(&index)
+		require.NoError(err)
+		require.Len(heights, len(blks))
+
+		f, err = os.Open(path)
+		require.NoError(err)
+		sr := NewSegmentReader(f, codec)
+		// read back in reverse order
+		for i := len(blks) - 1; i >= 0; i-- {
+			require.Equal(offsets[i], heights[blks[i].Height()])
+			blk, err := sr.ReadAt(offsets[i])
+			require.NoError(err)
+			require.True(blks[i].Equal(blk), "block %d", i)
+		}
+		_, err = sr.ReadAt(offsets[1] + 1)
+		require.Error(err)
+		_, err = sr.ReadAt(sw.Offset())
+		require.Equal(ErrInvalidSegmentRecord, errors.Cause(err))
+		require.NoError(f.Close())
+	}
+
+	_, err := ReadSegmentIndex(bytes.NewReader(make([]byte, 15)))
+	require.Equal(ErrInvalidSegmentRecord, errors.Cause(err))
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SigningPayloadVersion1 is the layout of SigningPayload
+	SigningPayloadVersion1 byte = 1
+	// SigningPayloadSize is the size of the payload returned by SigningPayload
+	SigningPayloadSize = 186
+
+	// signingPubKeySize is the size of an uncompressed secp256k1 public key
+	signingPubKeySize = 65
+)
+
+// SigningPayload returns the header of the block in a fixed binary layout, for hardware signers which parse and
+// display the fields one by one. All integers are big-endian, and the layout, which never changes for a given
+// layout version, is:
+//
+//	offset  size  field
+//	     0     1  layout version, SigningPayloadVersion1
+//	     1     4  block version
+//	     5     8  height
+//	    13     8  timestamp, seconds since the Unix epoch, signed
+//	    21     4  timestamp, nanoseconds within the second
+//	    25    32  prev hash
+//	    57    32  tx root
+//	    89    32  receipt root
+//	   121    65  producer public key, uncompressed secp256k1
+//
+// for a total of SigningPayloadSize bytes. A block without producer public key, like the genesis block, has no
+// signing payload.
+func (b *Block) SigningPayload() ([]byte, error) {
+	if b.pubkey == nil {
+		return nil, ErrMissingProducer
+	}
+	pubkey := b.pubkey.Bytes()
+	if len(pubkey) != signingPubKeySize {
+		return nil, errors.Errorf("producer public key has %d bytes, expecting %d", len(pubkey), signingPubKeySize)
+	}
+	var (
+		buf    = make([]byte, SigningPayloadSize)
+		ts     = b.Timestamp()
+		prev   = b.PrevHash()
+		txRoot = b.TxRoot()
+		rRoot  = b.ReceiptRoot()
+	)
+	buf[0] = SigningPayloadVersion1
+	binary.BigEndian.PutUint32(buf[1:], b.Version())
+	binary.BigEndian.PutUint64(buf[5:], b.Height())
+	binary.BigEndian.PutUint64(buf[13:], uint64(ts.Unix()))
+	binary.BigEndian.PutUint32(buf[21:], uint32(ts.Nanosecond()))
+	copy(buf[25:], prev[:])
+	copy(buf[57:], txRoot[:])
+	copy(buf[89:], rRoot[:])
+	copy(buf[121:], pubkey)
+	return buf, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningPayload(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 2)
+	blk.timestamp = time.Unix(1650000000, 123456789)
+	blk.receiptRoot = hash.Hash256b([]byte("receipts"))
+	payload, err := blk.SigningPayload()
+	require.NoError(err)
+	require.Len(payload, SigningPayloadSize)
+	require.Equal(186, SigningPayloadSize)
+
+	prev, txRoot, receiptRoot := blk.PrevHash(), blk.TxRoot(), blk.ReceiptRoot()
+	require.Equal(SigningPayloadVersion1, payload[0])
+	require.Equal(blk.Version(), binary.BigEndian.Uint32(payload[1:5]))
+	require.Equal(blk.Height(), binary.BigEndian.Uint64(payload[5:13]))
+	require.EqualValues(1650000000, binary.BigEndian.Uint64(payload[13:21]))
+	require.EqualValues(123456789, binary.BigEndian.Uint32(payload[21:25]))
+	require.Equal(prev[:], payload[25:57])
+	require.Equal(txRoot[:], payload[57:89])
+	require.Equal(receiptRoot[:], payload[89:121])
+	require.Equal(blk.PublicKey().Bytes(), payload[121:186])
+
+	again, err := blk.SigningPayload()
+	require.NoError(err)
+	require.Equal(payload, again)
+
+	blk.pubkey = nil
+	_, err = blk.SigningPayload()
+	require.Equal(ErrMissingProducer, err)
+}
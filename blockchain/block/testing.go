@@ -36,24 +36,28 @@ func NewTestingBuilder() *TestingBuilder {
 // SetVersion sets the protocol version for block which is building.
 func (b *TestingBuilder) SetVersion(v uint32) *TestingBuilder {
 	b.blk.Header.version = v
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetHeight sets the block height for block which is building.
 func (b *TestingBuilder) SetHeight(h uint64) *TestingBuilder {
 	b.blk.Header.height = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetTimeStamp sets the time stamp for block which is building.
 func (b *TestingBuilder) SetTimeStamp(ts time.Time) *TestingBuilder {
 	b.blk.Header.timestamp = ts
+	b.blk.Header.resetHashCache()
 	return b
 }
 
 // SetPrevBlockHash sets the previous block hash for block which is building.
 func (b *TestingBuilder) SetPrevBlockHash(h hash.Hash256) *TestingBuilder {
 	b.blk.Header.prevBlockHash = h
+	b.blk.Header.resetHashCache()
 	return b
 }
 
@@ -88,6 +92,7 @@ func (b *TestingBuilder) SignAndBuild(signerPrvKey crypto.PrivateKey) (Block, er
 		return Block{}, errors.New("failed to sign block")
 	}
 	b.blk.Header.blockSig = sig
+	b.blk.Header.resetHashCache()
 	return b.blk, nil
 }
 
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+
+	"github.com/iotexproject/iotex-core/crypto"
+)
+
+// Errors
+var (
+	ErrNoReceipts    = errors.New("block has no receipts")
+	ErrTopicNotFound = errors.New("topic not found in block")
+)
+
+// TopicProof is the merkle proof that a log emitted by Address carried Topic, against the topic commitment
+// root of a block
+type TopicProof struct {
+	Address string
+	Topic   hash.Hash256
+	// Index is the position of the (address, topic) pair among the sorted pairs of the block
+	Index uint32
+	// Siblings are the sibling nodes on the path from the pair to the root, from the bottom up
+	Siblings []hash.Hash256
+}
+
+type topicPair struct {
+	addr  string
+	topic hash.Hash256
+}
+
+// leaf returns the leaf of the pair in the topic commitment tree, the topic has a fixed size so the
+// concatenation is unambiguous
+func (p topicPair) leaf() hash.Hash256 {
+	return hash.Hash256b(append([]byte(p.addr), p.topic[:]...))
+}
+
+// TopicCommitmentRoot returns the merkle root over the unique (address, topic) pairs of all logs in the
+// receipts, sorted by address then topic. The tree follows the same scheme as the tx root. It returns
+// hash.ZeroHash256 if no log carries a topic, and ErrNoReceipts if the block has actions but no receipts.
+func (b *Block) TopicCommitmentRoot() (hash.Hash256, error) {
+	pairs, err := b.topicPairs()
+	if err != nil {
+		return hash.ZeroHash256, err
+	}
+	if len(pairs) == 0 {
+		return hash.ZeroHash256, nil
+	}
+	return crypto.NewMerkleTree(topicLeaves(pairs)).HashTree(), nil
+}
+
+// TopicInclusionProof returns the proof that a log emitted by addr carried topic, or ErrTopicNotFound
+func (b *Block) TopicInclusionProof(addr string, topic hash.Hash256) (TopicProof, error) {
+	pairs, err := b.topicPairs()
+	if err != nil {
+		return TopicProof{}, err
+	}
+	target := topicPair{addr, topic}
+	i := sort.Search(len(pairs), func(i int) bool {
+		return !pairs[i].less(target)
+	})
+	if i == len(pairs) || pairs[i] != target {
+		return TopicProof{}, errors.Wrapf(ErrTopicNotFound, "address %s, topic %x", addr, topic)
+	}
+	return TopicProof{
+		Address:  addr,
+		Topic:    topic,
+		Index:    uint32(i),
+		Siblings: merkleProof(topicLeaves(pairs), i),
+	}, nil
+}
+
+// VerifyTopicProof returns true if the proof shows the (address, topic) pair is included in root
+func VerifyTopicProof(root hash.Hash256, proof TopicProof) bool {
+	leaf := topicPair{proof.Address, proof.Topic}.leaf()
+	return root != hash.ZeroHash256 && merkleProofRoot(leaf, proof.Index, proof.Siblings, hashPair) == root
+}
+
+func (b *Block) topicPairs() ([]topicPair, error) {
+	if b.Receipts == nil && len(b.Actions) > 0 {
+		return nil, ErrNoReceipts
+	}
+	seen := make(map[topicPair]struct{})
+	pairs := []topicPair{}
+	for _, r := range b.Receipts {
+		if r == nil {
+			continue
+		}
+		for _, l := range r.Logs() {
+			for _, topic := range l.Topics {
+				p := topicPair{l.Address, topic}
+				if _, ok := seen[p]; ok {
+					continue
+				}
+				seen[p] = struct{}{}
+				pairs = append(pairs, p)
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].less(pairs[j])
+	})
+	return pairs, nil
+}
+
+func (p topicPair) less(other topicPair) bool {
+	if p.addr != other.addr {
+		return p.addr < other.addr
+	}
+	return bytes.Compare(p.topic[:], other.topic[:]) < 0
+}
+
+func topicLeaves(pairs []topicPair) []hash.Hash256 {
+	leaves := make([]hash.Hash256, len(pairs))
+	for i := range pairs {
+		leaves[i] = pairs[i].leaf()
+	}
+	return leaves
+}
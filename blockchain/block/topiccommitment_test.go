@@ -0,0 +1,79 @@
+// Copyright (c) 2022 IoTeX Foundation
+// This is an alpha (internal) release and is not suitable for production. This source code is provided 'as is' and no
+// warranties are given as to title or non-infringement, merchantability or fitness for purpose and, to the extent
+// permitted by law, all liability for your use of the code is disclaimed. This source code is governed by Apache
+// License 2.0 that can be found in the LICENSE file.
+
+package block
+
+import (
+	"testing"
+
+	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/action"
+	"github.com/iotexproject/iotex-core/test/identityset"
+)
+
+func TestTopicCommitment(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	_, err := blk.TopicCommitmentRoot()
+	require.Equal(ErrNoReceipts, errors.Cause(err))
+
+	// every receipt emits "topic 0" from address 0, and the logs of a receipt repeat topics
+	receipts := makeReceipts(t, blk, 3)
+	transfer := hash.Hash256b([]byte("Transfer"))
+	for _, r := range receipts {
+		r.AddLogs(&action.Log{
+			Address: identityset.Address(1).String(),
+			Topics:  []hash.Hash256{transfer, transfer, hash.Hash256b([]byte("topic 1"))},
+		})
+	}
+	require.NoError(blk.SetReceipts(receipts))
+	root, err := blk.TopicCommitmentRoot()
+	require.NoError(err)
+	require.NotEqual(hash.ZeroHash256, root)
+	pairs, err := blk.topicPairs()
+	require.NoError(err)
+	// (0, topic 0), (1, topic 1), (1, Transfer), (2, topic 2)
+	require.Len(pairs, 4)
+	for i := 1; i < len(pairs); i++ {
+		require.True(pairs[i-1].less(pairs[i]))
+	}
+
+	for _, p := range pairs {
+		proof, err := blk.TopicInclusionProof(p.addr, p.topic)
+		require.NoError(err)
+		require.True(VerifyTopicProof(root, proof))
+
+		// a proof does not verify for another address or topic
+		other := proof
+		other.Topic = hash.Hash256b([]byte("other"))
+		require.False(VerifyTopicProof(root, other))
+		other = proof
+		other.Address = identityset.Address(5).String()
+		require.False(VerifyTopicProof(root, other))
+	}
+	_, err = blk.TopicInclusionProof(identityset.Address(2).String(), transfer)
+	require.Equal(ErrTopicNotFound, errors.Cause(err))
+
+	// the root does not depend on the order of receipts
+	reversed := make([]*action.Receipt, len(receipts))
+	for i := range receipts {
+		reversed[len(receipts)-1-i] = receipts[i]
+	}
+	require.NoError(blk.SetReceipts(reversed))
+	again, err := blk.TopicCommitmentRoot()
+	require.NoError(err)
+	require.Equal(root, again)
+
+	// no topic at all
+	require.NoError(blk.SetReceipts(makeReceipts(t, blk, 0)))
+	root, err = blk.TopicCommitmentRoot()
+	require.NoError(err)
+	require.Equal(hash.ZeroHash256, root)
+}
@@ -10,6 +10,7 @@ import (
 	"math/big"
 
 	"github.com/iotexproject/go-pkgs/hash"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/iotexproject/iotex-core/action"
@@ -18,6 +19,10 @@ import (
 )
 
 func calculateTxRoot(acts []action.SealedEnvelope) (hash.Hash256, error) {
+	return calculateTxRootWith(acts, hash.Hash256b)
+}
+
+func calculateTxRootWith(acts []action.SealedEnvelope, hasher func([]byte) hash.Hash256) (hash.Hash256, error) {
 	h := make([]hash.Hash256, 0, len(acts))
 	for _, act := range acts {
 		actHash, err := act.Hash()
@@ -30,7 +35,21 @@ func calculateTxRoot(acts []action.SealedEnvelope) (hash.Hash256, error) {
 	if len(h) == 0 {
 		return hash.ZeroHash256, nil
 	}
-	return crypto.NewMerkleTree(h).HashTree(), nil
+	return crypto.NewMerkleTree(h).HashTreeWith(hasher), nil
+}
+
+// CalculateTxRootFromHashes returns the tx root of a block from the hashes of its actions, in the order of
+// the actions in the block. It allows to verify the tx root without holding the actions.
+func CalculateTxRootFromHashes(hashes []hash.Hash256) (hash.Hash256, error) {
+	for i, h := range hashes {
+		if h == hash.ZeroHash256 {
+			return hash.ZeroHash256, errors.Errorf("action hash %d is empty", i)
+		}
+	}
+	if len(hashes) == 0 {
+		return hash.ZeroHash256, nil
+	}
+	return crypto.NewMerkleTree(hashes).HashTree(), nil
 }
 
 // calculateTransferAmount returns the calculated transfer amount
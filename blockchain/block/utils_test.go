@@ -78,3 +78,32 @@ func TestBody_CalculateTransferAmount(t *testing.T) {
 	amount := calculateTransferAmount(sevlps)
 	requireT.Equal(amount, transferAmount)
 }
+
+func TestCalculateTxRootFromHashes(t *testing.T) {
+	require := require.New(t)
+
+	for _, n := range []int{0, 1, 2, 5, 10} {
+		blk := makeBlock(t, n)
+		hashes := make([]hash.Hash256, 0, n)
+		for _, selp := range blk.Actions {
+			h, err := selp.Hash()
+			require.NoError(err)
+			hashes = append(hashes, h)
+		}
+		expected, err := blk.CalculateTxRoot()
+		require.NoError(err)
+		root, err := CalculateTxRootFromHashes(hashes)
+		require.NoError(err)
+		require.Equal(expected, root)
+		if n > 1 {
+			// the order of the hashes matters
+			hashes[0], hashes[1] = hashes[1], hashes[0]
+			root, err = CalculateTxRootFromHashes(hashes)
+			require.NoError(err)
+			require.NotEqual(expected, root)
+		}
+	}
+
+	_, err := CalculateTxRootFromHashes([]hash.Hash256{hash.Hash256b([]byte("action")), hash.ZeroHash256})
+	require.Error(err)
+}
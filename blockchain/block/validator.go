@@ -9,6 +9,7 @@ package block
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/iotexproject/iotex-core/action"
 	"github.com/pkg/errors"
@@ -20,6 +21,59 @@ type Validator interface {
 	Validate(ctx context.Context, block *Block) error
 }
 
+// BlockValidator is a check of a block on its own, validators can be composed with Block.RunValidators
+type BlockValidator interface {
+	Validate(*Block) error
+}
+
+// BlockValidatorFunc adapts a function to a BlockValidator
+type BlockValidatorFunc func(*Block) error
+
+// Validate calls f(blk)
+func (f BlockValidatorFunc) Validate(blk *Block) error {
+	return f(blk)
+}
+
+var (
+	// TxRootValidator verifies the tx root of the header matches the actions
+	TxRootValidator BlockValidator = BlockValidatorFunc((*Block).VerifyTxRoot)
+
+	// SignatureValidator verifies the producer's signature of the header, except for the genesis block, and the
+	// sender's signature of every action
+	SignatureValidator BlockValidator = BlockValidatorFunc(func(blk *Block) error {
+		if blk.Height() > 0 && !blk.VerifySignature() {
+			return errors.Wrapf(ErrInvalidSignature, "block %d", blk.Height())
+		}
+		for i := range blk.Actions {
+			if err := blk.Actions[i].VerifySignature(); err != nil {
+				return errors.Wrapf(ErrInvalidSignature, "action %d: %v", i, err)
+			}
+		}
+		return nil
+	})
+)
+
+// TimestampValidator returns a validator verifying the timestamp is set and no more than maxDrift ahead of the
+// local clock
+func TimestampValidator(maxDrift time.Duration) BlockValidator {
+	return BlockValidatorFunc(func(blk *Block) error {
+		if blk.Timestamp().IsZero() {
+			return errors.Wrapf(ErrZeroTimestamp, "block %d", blk.Height())
+		}
+		return blk.verifyTimestampNotAfter(time.Now().Add(maxDrift))
+	})
+}
+
+// RunValidators runs the validators in order, and returns the error of the first one that fails
+func (b *Block) RunValidators(vs ...BlockValidator) error {
+	for _, v := range vs {
+		if err := v.Validate(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type validator struct {
 	subValidator Validator
 	validators   []action.SealedEnvelopeValidator
@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -62,3 +63,50 @@ func TestValidator(t *testing.T) {
 	require.Contains(v.Validate(ctx, &nblk).Error(), "MockChainManager nonce error")
 
 }
+
+func TestRunValidators(t *testing.T) {
+	require := require.New(t)
+
+	blk := makeBlock(t, 3)
+	require.NoError(blk.RunValidators())
+	require.NoError(blk.RunValidators(TxRootValidator, SignatureValidator, TimestampValidator(time.Minute)))
+
+	// a permissioned chain only accepts blocks produced by a whitelist
+	var called []string
+	whitelist := func(producer string) BlockValidator {
+		return BlockValidatorFunc(func(blk *Block) error {
+			called = append(called, "whitelist")
+			return blk.VerifyProducerIn(map[string]struct{}{producer: {}})
+		})
+	}
+	counter := BlockValidatorFunc(func(*Block) error {
+		called = append(called, "counter")
+		return nil
+	})
+	require.NoError(blk.RunValidators(counter, whitelist(identityset.Address(0).String())))
+	require.Equal([]string{"counter", "whitelist"}, called)
+
+	// the second validator fails and the third is not run
+	called = nil
+	err := blk.RunValidators(counter, whitelist(identityset.Address(1).String()), counter)
+	require.Equal(ErrUnauthorizedProducer, errors.Cause(err))
+	require.Equal([]string{"counter", "whitelist"}, called)
+
+	// ready-made validators
+	blk.txRoot[0]++
+	require.Equal(ErrTxRootMismatch, errors.Cause(blk.RunValidators(TxRootValidator)))
+	require.Equal(ErrInvalidSignature, errors.Cause(blk.RunValidators(SignatureValidator)))
+	blk.txRoot[0]--
+	blk.Actions[0], blk.Actions[1] = blk.Actions[1], blk.Actions[0]
+	require.NoError(blk.RunValidators(SignatureValidator))
+	require.Equal(ErrTxRootMismatch, errors.Cause(blk.RunValidators(SignatureValidator, TxRootValidator)))
+
+	future, err := NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(time.Now().Add(time.Hour)).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	require.Equal(ErrTimestampInFuture, errors.Cause(future.RunValidators(TimestampValidator(time.Minute))))
+	require.NoError(future.RunValidators(TimestampValidator(2 * time.Hour)))
+	require.Equal(ErrZeroTimestamp, errors.Cause((&Block{}).RunValidators(TimestampValidator(time.Minute))))
+}
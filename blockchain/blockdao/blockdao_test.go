@@ -203,10 +203,10 @@ func TestBlockDAO(t *testing.T) {
 			require.Equal(tipBlk.Height(), height)
 			blk, err := dao.GetBlock(hash)
 			require.NoError(err)
-			require.Equal(tipBlk, blk)
+			require.True(tipBlk.Equal(blk))
 			blk, err = dao.GetBlockByHeight(height)
 			require.NoError(err)
-			require.Equal(tipBlk, blk)
+			require.True(tipBlk.Equal(blk))
 			r, err := dao.GetReceipts(height)
 			require.NoError(err)
 			require.Equal(len(receipts[i]), len(r))
@@ -329,10 +329,10 @@ func TestBlockDAO(t *testing.T) {
 			require.Equal(tipHeight, height)
 			blk, err := dao.GetBlock(h)
 			require.NoError(err)
-			require.Equal(tipBlk, blk)
+			require.True(tipBlk.Equal(blk))
 			blk, err = dao.GetBlockByHeight(height)
 			require.NoError(err)
-			require.Equal(tipBlk, blk)
+			require.True(tipBlk.Equal(blk))
 
 			// test BlockDAO's API, 2nd loop to test LRU cache
 			for i := 0; i < 2; i++ {
@@ -9,8 +9,14 @@ package rolldpos
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/iotexproject/iotex-core/blockchain/block"
+	"github.com/iotexproject/iotex-core/endorsement"
+	"github.com/iotexproject/iotex-core/test/identityset"
+	"github.com/iotexproject/iotex-core/testutil"
 )
 
 func TestConsensusVote(t *testing.T) {
@@ -27,3 +33,22 @@ func TestConsensusVote(t *testing.T) {
 	require.Equal(0, bytes.Compare(hash, cvote.BlockHash()))
 	require.Equal(PROPOSAL, cvote.Topic())
 }
+
+func TestConsensusVoteBlockEndorsement(t *testing.T) {
+	require := require.New(t)
+	blk, err := block.NewTestingBuilder().
+		SetHeight(1).
+		SetTimeStamp(testutil.TimestampNow()).
+		SignAndBuild(identityset.PrivateKey(0))
+	require.NoError(err)
+	blkHash := blk.HashProposal()
+
+	// the block accepts endorsements of the commit vote only
+	en, err := endorsement.Endorse(identityset.PrivateKey(1), NewConsensusVote(blkHash[:], COMMIT), time.Now())
+	require.NoError(err)
+	require.NoError(blk.AddEndorsement(en))
+	en, err = endorsement.Endorse(identityset.PrivateKey(2), NewConsensusVote(blkHash[:], LOCK), time.Now())
+	require.NoError(err)
+	require.Error(blk.AddEndorsement(en))
+	require.Len(blk.Endorsements(), 1)
+}
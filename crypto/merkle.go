@@ -51,6 +51,16 @@ func (mk *Merkle) HashTree() hash.Hash256 {
 		return mk.root
 	}
 
+	mk.root = mk.HashTreeWith(hash.Hash256b)
+	return mk.root
+}
+
+// HashTreeWith calculates the root hash of a merkle tree, using hasher to compute the inner nodes
+func (mk *Merkle) HashTreeWith(hasher func([]byte) hash.Hash256) hash.Hash256 {
+	if mk.size == 1 {
+		return mk.leaf[0]
+	}
+
 	length := mk.size >> 1
 	merkle := make([]hash.Hash256, length)
 
@@ -58,7 +68,7 @@ func (mk *Merkle) HashTree() hash.Hash256 {
 	for i := 0; i < length; i++ {
 		h := mk.leaf[i<<1][:]
 		h = append(h, mk.leaf[i<<1+1][:]...)
-		merkle[i] = hash.Hash256b(h)
+		merkle[i] = hasher(h)
 	}
 
 	for length > 1 {
@@ -71,11 +81,10 @@ func (mk *Merkle) HashTree() hash.Hash256 {
 		for i := 0; i < length; i++ {
 			h := merkle[i<<1][:]
 			h = append(h, merkle[i<<1+1][:]...)
-			merkle[i] = hash.Hash256b(h)
+			merkle[i] = hasher(h)
 		}
 		merkle = merkle[0:length]
 	}
 
-	mk.root = merkle[0]
-	return mk.root
+	return merkle[0]
 }
@@ -40,4 +40,5 @@ func TestMerkleTree(t *testing.T) {
 	rootHash := m.HashTree()
 	rootHashHex := hex.EncodeToString(rootHash[:])
 	assert.Equal(t, "4de26a6d1d6618f7bfeb3d168e37ef645db94c2d558bf8c3546d1311877ddffa", rootHashHex)
+	assert.Equal(t, rootHash, m.HashTreeWith(hash.Hash256b))
 }
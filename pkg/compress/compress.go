@@ -7,9 +7,12 @@
 package compress
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"io"
+	"sort"
 
 	"github.com/golang/snappy"
 	"github.com/pkg/errors"
@@ -23,9 +26,39 @@ const (
 
 // error definition
 var (
-	ErrInputEmpty = errors.New("input cannot be empty")
+	ErrInputEmpty        = errors.New("input cannot be empty")
+	ErrCorruptGzipStream = errors.New("corrupt gzip stream")
+	ErrUnsupportedCodec  = errors.New("unsupported codec")
 )
 
+// Codec is the name of a compressor, either Gzip or Snappy
+type Codec string
+
+// Codecs lists the supported codecs
+var Codecs = []Codec{Gzip, Snappy}
+
+// Compress compresses the input with the codec, it returns ErrUnsupportedCodec rather than panicking
+// if the codec is unknown
+func (c Codec) Compress(value []byte) ([]byte, error) {
+	if !c.supported() {
+		return nil, errors.Wrapf(ErrUnsupportedCodec, "codec %q", c)
+	}
+	return Compress(value, string(c))
+}
+
+// Decompress decompresses the input with the codec, it returns ErrUnsupportedCodec rather than panicking
+// if the codec is unknown
+func (c Codec) Decompress(value []byte) ([]byte, error) {
+	if !c.supported() {
+		return nil, errors.Wrapf(ErrUnsupportedCodec, "codec %q", c)
+	}
+	return Decompress(value, string(c))
+}
+
+func (c Codec) supported() bool {
+	return c == Gzip || c == Snappy
+}
+
 // Compress compresses input according to compressor
 func Compress(value []byte, compressor string) ([]byte, error) {
 	if value == nil {
@@ -81,6 +114,121 @@ func DecompGzip(data []byte) ([]byte, error) {
 	return io.ReadAll(r)
 }
 
+// DecompGzipStream reads consecutive gzip members from r, and calls fn with the uncompressed content
+// of each member in order. It returns nil at the end of the stream, ErrCorruptGzipStream if a member
+// cannot be decompressed, or the first error returned by fn.
+func DecompGzipStream(r io.Reader, fn func([]byte) error) error {
+	var (
+		br = bufio.NewReader(r)
+		zr *gzip.Reader
+		n  int
+	)
+	for ; ; n++ {
+		if _, err := br.Peek(1); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to read gzip member after %d members", n)
+		}
+		var err error
+		if zr == nil {
+			zr, err = gzip.NewReader(br)
+		} else {
+			err = zr.Reset(br)
+		}
+		if err != nil {
+			return errors.Wrapf(ErrCorruptGzipStream, "%d members read: %v", n, err)
+		}
+		// stop at the end of the current member, instead of reading on into the next one
+		zr.Multistream(false)
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			return errors.Wrapf(ErrCorruptGzipStream, "%d members read: %v", n, err)
+		}
+		if err := fn(data); err != nil {
+			return err
+		}
+	}
+}
+
+// MaxDictSize is the size of the DEFLATE window, bytes of a preset dictionary beyond it are never referenced
+const MaxDictSize = 32 << 10
+
+// dictSegmentLen is the length of the substrings BuildDeflateDict counts across samples
+const dictSegmentLen = 8
+
+// CompDeflateDict compresses the input with DEFLATE, using dict as preset dictionary, which makes small inputs
+// resembling the dictionary much smaller. The output is a zlib stream, not gzip, since the gzip format has no
+// preset dictionary; the zlib header records the checksum of the dictionary. DecompDeflateDict must be given
+// the same dictionary.
+func CompDeflateDict(data, dict []byte) ([]byte, error) {
+	var bb bytes.Buffer
+	w, err := zlib.NewWriterLevelDict(&bb, zlib.BestCompression, dict)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return bb.Bytes(), nil
+}
+
+// DecompDeflateDict decompresses the output of CompDeflateDict, dict must be the dictionary used to compress
+func DecompDeflateDict(data, dict []byte) ([]byte, error) {
+	r, err := zlib.NewReaderDict(bytes.NewReader(data), dict)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// BuildDeflateDict builds a preset dictionary of at most size bytes for CompDeflateDict from sample inputs, such as
+// recent blocks. It is made of the substrings found in the most samples, the most common at the end since
+// DEFLATE encodes closer matches with fewer bits. The result only depends on the samples, so nodes building it
+// from the same samples get the same dictionary, which can also be built once and shipped as a file.
+func BuildDeflateDict(samples [][]byte, size int) []byte {
+	if size > MaxDictSize {
+		size = MaxDictSize
+	}
+	counts := make(map[string]int)
+	for _, sample := range samples {
+		seen := make(map[string]struct{})
+		for i := 0; i+dictSegmentLen <= len(sample); i++ {
+			seg := string(sample[i : i+dictSegmentLen])
+			if _, ok := seen[seg]; ok {
+				continue
+			}
+			seen[seg] = struct{}{}
+			counts[seg]++
+		}
+	}
+	segs := make([]string, 0, len(counts))
+	for seg, n := range counts {
+		// a substring of a single sample is unlikely to appear in other inputs
+		if n > 1 {
+			segs = append(segs, seg)
+		}
+	}
+	sort.Slice(segs, func(i, j int) bool {
+		if counts[segs[i]] != counts[segs[j]] {
+			return counts[segs[i]] > counts[segs[j]]
+		}
+		return segs[i] < segs[j]
+	})
+	if n := size / dictSegmentLen; len(segs) > n {
+		segs = segs[:n]
+	}
+	dict := make([]byte, 0, len(segs)*dictSegmentLen)
+	for i := len(segs) - 1; i >= 0; i-- {
+		dict = append(dict, segs[i]...)
+	}
+	return dict
+}
+
 // CompSnappy uses Snappy to compress the input bytes
 func CompSnappy(data []byte) ([]byte, error) {
 	return snappy.Encode(nil, data), nil
@@ -7,9 +7,11 @@
 package compress
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,3 +48,77 @@ func TestCompressDataBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestCodec(t *testing.T) {
+	r := require.New(t)
+
+	data := bytes.Repeat([]byte("codec"), 100)
+	for _, c := range Codecs {
+		v, err := c.Compress(data)
+		r.NoError(err)
+		expected, err := Compress(data, string(c))
+		r.NoError(err)
+		r.Equal(expected, v)
+		v, err = c.Decompress(v)
+		r.NoError(err)
+		r.Equal(data, v)
+	}
+	_, err := Codec("invalid").Compress(data)
+	r.Equal(ErrUnsupportedCodec, errors.Cause(err))
+	_, err = Codec("").Decompress(data)
+	r.Equal(ErrUnsupportedCodec, errors.Cause(err))
+}
+
+func TestDecompGzipStream(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		stream  bytes.Buffer
+		members = [][]byte{[]byte("first"), {}, []byte("third member")}
+	)
+	for _, m := range members {
+		v, err := CompGzip(m)
+		r.NoError(err)
+		stream.Write(v)
+	}
+	complete := stream.Bytes()
+
+	var read [][]byte
+	r.NoError(DecompGzipStream(bytes.NewReader(complete), func(b []byte) error {
+		read = append(read, b)
+		return nil
+	}))
+	r.Equal(members, read)
+
+	// empty stream
+	r.NoError(DecompGzipStream(bytes.NewReader(nil), func([]byte) error {
+		r.Fail("no member expected")
+		return nil
+	}))
+
+	// corrupt and truncated last member
+	first, err := CompGzip(members[0])
+	r.NoError(err)
+	for _, data := range [][]byte{
+		append(append([]byte{}, first...), 1, 2, 3),
+		complete[:len(complete)-4],
+	} {
+		read = read[:0]
+		err = DecompGzipStream(bytes.NewReader(data), func(b []byte) error {
+			read = append(read, b)
+			return nil
+		})
+		r.Equal(ErrCorruptGzipStream, errors.Cause(err))
+		r.NotEmpty(read)
+	}
+	r.Contains(err.Error(), "2 members read")
+
+	// callback error stops the iteration
+	errStop := errors.New("stop")
+	var n int
+	r.Equal(errStop, DecompGzipStream(bytes.NewReader(complete), func([]byte) error {
+		n++
+		return errStop
+	}))
+	r.Equal(1, n)
+}
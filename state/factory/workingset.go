@@ -527,8 +527,8 @@ func (ws *workingSet) ValidateBlock(ctx context.Context, blk *block.Block) error
 	if err != nil {
 		return err
 	}
-	if !blk.VerifyDeltaStateDigest(digest) {
-		return block.ErrDeltaStateMismatch
+	if err := blk.VerifyDeltaStateDigest(digest); err != nil {
+		return err
 	}
 	if !blk.VerifyReceiptRoot(calculateReceiptRoot(ws.receipts)) {
 		return block.ErrReceiptRootMismatch